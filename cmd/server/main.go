@@ -1,100 +1,101 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/gin-gonic/gin"
-
-	"github.com/xiaoxin/blog-backend/internal/middleware"
-	"github.com/xiaoxin/blog-backend/internal/models"
-	"github.com/xiaoxin/blog-backend/internal/routes"
 	"github.com/xiaoxin/blog-backend/pkg/config"
 	"github.com/xiaoxin/blog-backend/pkg/database"
-	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
 	"github.com/xiaoxin/blog-backend/pkg/logger"
-	"github.com/xiaoxin/blog-backend/pkg/redis"
 )
 
-func main() {
-	// 1. 加载配置
-	cfg, err := config.LoadConfig("config/config.yaml")
-	if err != nil {
-		log.Fatalf("加载配置文件失败: %v", err)
-	}
+const usage = `用法: server [--config 配置文件] [--env 环境] <命令> [参数]
 
-	// 2. 初始化日志系统
-	if err := logger.InitLogger(&cfg.Log); err != nil {
-		log.Fatalf("初始化日志系统失败: %v", err)
-	}
-	defer logger.Sync()
+命令:
+  serve                         启动HTTP服务（默认）
+  migrate <up|down|status|to>   数据库迁移
+  user <create|set-role|reset-password|disable|enable>
+                                用户管理
+  seed                          生成开发用的示例数据
+  openapi [--check]             生成接口文档，或检查路由文档是否完整
 
-	logger.Info("日志系统初始化完成")
+执行 "server <命令> -h" 查看命令的详细用法。
 
-	// 3. 初始化数据库
-	if err := database.InitDB(&cfg.Database); err != nil {
-		logger.Fatalf("初始化数据库失败: %v", err)
-	}
-	defer database.CloseDB()
+全局参数:
+`
 
-	// 自动迁移数据表
-	if err := database.AutoMigrate(
-		&models.User{},
-		&models.Category{},
-		&models.Tag{},
-		&models.Article{},
-		&models.Comment{},
-	); err != nil {
-		logger.Fatalf("数据表迁移失败: %v", err)
-	}
-	logger.Info("数据表迁移完成")
+// options 全局命令行参数
+type options struct {
+	configPath string
+	env        string
+}
 
-	// 4. 初始化Redis
-	if err := redis.InitRedis(&cfg.Redis); err != nil {
-		logger.Fatalf("初始化Redis失败: %v", err)
+func main() {
+	var opts options
+	flag.StringVar(&opts.configPath, "config", "config/config.yaml", "配置文件路径")
+	flag.StringVar(&opts.env, "env", os.Getenv("BLOG_ENV"), "运行环境，用于加载覆盖配置文件，如 production 对应 config.production.yaml")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
 	}
-	defer redis.CloseRedis()
+	flag.Parse()
 
-	// 5. 初始化JWT
-	pkgjwt.InitJWT(cfg.JWT.Secret)
-	logger.Info("JWT初始化完成")
+	// 未指定命令时启动服务，兼容原有的启动方式
+	cmd, args := "serve", flag.Args()
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
 
-	// 6. 设置Gin模式
-	gin.SetMode(cfg.App.Mode)
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(opts, args)
+	case "migrate":
+		err = runMigrate(opts, args)
+	case "user":
+		err = runUser(opts, args)
+	case "seed":
+		err = runSeed(opts, args)
+	case "openapi":
+		err = runOpenAPI(opts, args)
+	case "help", "-h", "--help":
+		flag.Usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n", cmd)
+		flag.Usage()
+		os.Exit(2)
+	}
 
-	// 7. 创建路由引擎
-	r := gin.New()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	// 8. 使用中间件
-	r.Use(middleware.Logger())
-	r.Use(gin.Recovery())
-	r.Use(middleware.CORS())
+// setup 加载配置并初始化日志和数据库，返回清理函数
+func setup(opts options) (*config.Config, func(), error) {
+	cfg, err := config.LoadConfig(opts.configPath, opts.env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("加载配置文件失败: %w", err)
+	}
 
-	// 9. 设置路由
-	routes.SetupRoutes(r)
+	if err := logger.InitLogger(&cfg.Log); err != nil {
+		return nil, nil, fmt.Errorf("初始化日志系统失败: %w", err)
+	}
 
-	// 10. 启动服务
-	addr := fmt.Sprintf(":%d", cfg.App.Port)
-	logger.Infof("服务启动成功，监听地址: %s", addr)
-	logger.Infof("应用名称: %s", cfg.App.Name)
-	logger.Infof("应用版本: %s", cfg.App.Version)
-	logger.Infof("运行模式: %s", cfg.App.Mode)
+	if err := database.InitDB(&cfg.Database); err != nil {
+		logger.Sync()
+		return nil, nil, fmt.Errorf("初始化数据库失败: %w", err)
+	}
 
-	// 11. 优雅关闭
-	go func() {
-		if err := r.Run(addr); err != nil {
-			logger.Fatalf("服务启动失败: %v", err)
+	cleanup := func() {
+		if err := database.CloseDB(); err != nil {
+			log.Printf("关闭数据库失败: %v", err)
 		}
-	}()
-
-	// 12. 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info("正在关闭服务...")
-	logger.Info("服务已关闭")
+		logger.Sync()
+	}
+	return cfg, cleanup, nil
 }
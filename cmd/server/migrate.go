@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/xiaoxin/blog-backend/pkg/database"
+)
+
+const migrateUsage = `用法: server migrate <命令>
+
+命令:
+  up            执行所有未执行的迁移
+  down [n]      回滚最近 n 个迁移（默认1）
+  status        查看迁移状态
+  to <version>  迁移到指定版本（0 表示回滚全部）
+`
+
+// runMigrate 数据库迁移命令
+func runMigrate(opts options, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(fs.Output(), migrateUsage) }
+	_ = fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return errors.New("缺少迁移命令")
+	}
+
+	_, cleanup, err := setup(opts)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	migrator, err := database.NewMigrator(database.GetDB())
+	if err != nil {
+		return fmt.Errorf("加载数据库迁移失败: %w", err)
+	}
+
+	ctx := context.Background()
+	switch cmd := fs.Arg(0); cmd {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("迁移完成，当前版本: %d\n", migrator.Latest())
+		return nil
+	case "down":
+		steps := 1
+		if fs.NArg() > 1 {
+			if steps, err = strconv.Atoi(fs.Arg(1)); err != nil {
+				return fmt.Errorf("无效的回滚步数: %s", fs.Arg(1))
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "to":
+		if fs.NArg() < 2 {
+			return errors.New("请指定目标版本")
+		}
+		version, err := strconv.ParseUint(fs.Arg(1), 10, 32)
+		if err != nil {
+			return fmt.Errorf("无效的版本号: %s", fs.Arg(1))
+		}
+		return migrator.To(ctx, uint(version))
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		fs.Usage()
+		return fmt.Errorf("未知的迁移命令: %s", cmd)
+	}
+}
+
+// printMigrationStatus 打印迁移状态
+func printMigrationStatus(ctx context.Context, migrator *database.Migrator) error {
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-8s %-30s %-8s %s\n", "VERSION", "NAME", "APPLIED", "APPLIED AT")
+	for _, s := range status {
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-8d %-30s %-8t %s\n", s.Version, s.Name, s.Applied, appliedAt)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/container"
+	"github.com/xiaoxin/blog-backend/internal/repository/memory"
+	"github.com/xiaoxin/blog-backend/internal/routes"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// runOpenAPI 生成接口文档，或检查是否所有路由都有文档
+func runOpenAPI(opts options, args []string) error {
+	fs := flag.NewFlagSet("openapi", flag.ExitOnError)
+	output := fs.String("o", "", "输出文件，默认输出到标准输出")
+	check := fs.Bool("check", false, "只检查路由文档是否完整，不输出文档")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server openapi [参数]\n\n根据路由表生成 OpenAPI 3 文档，有路由缺少文档时返回错误。\n\n参数:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	// 只需要配置中的应用名称、版本和监控路径，不连接数据库
+	if _, err := config.LoadConfig(opts.configPath, opts.env); err != nil {
+		return fmt.Errorf("加载配置文件失败: %w", err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	routes.SetupRoutes(r, container.New(memory.NewRepositories()))
+
+	doc, problems := routes.OpenAPI(r)
+	if len(problems) > 0 {
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+		}
+		return fmt.Errorf("接口文档不完整，共 %d 处问题，请在 internal/routes/docs.go 中补充", len(problems))
+	}
+	if *check {
+		fmt.Printf("接口文档检查通过，共 %d 个路径\n", len(doc.Paths))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return fmt.Errorf("写入文档失败: %w", err)
+	}
+	fmt.Printf("接口文档已写入 %s\n", *output)
+	return nil
+}
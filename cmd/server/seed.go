@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/xiaoxin/blog-backend/internal/seed"
+	"github.com/xiaoxin/blog-backend/pkg/database"
+)
+
+// runSeed 生成开发数据
+func runSeed(opts options, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	seedValue := fs.Int64("seed", 1, "随机种子，相同种子生成相同数据")
+	users := fs.Int("users", 20, "用户数量（第一个用户为管理员 admin）")
+	categories := fs.Int("categories", 8, "分类数量")
+	tags := fs.Int("tags", 15, "标签数量")
+	articles := fs.Int("articles", 100, "文章数量")
+	comments := fs.Int("comments", 300, "评论数量")
+	force := fs.Bool("force", false, "允许在 release 模式下执行")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server seed [参数]\n\n向空数据库写入开发用的示例数据。\n\n参数:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	cfg, cleanup, err := setup(opts)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if cfg.App.Mode == "release" && !*force {
+		return errors.New("release 模式下禁止生成示例数据，如确需执行请加 --force")
+	}
+
+	// 管理命令始终读主库，避免副本复制延迟
+	result, err := seed.Run(database.WithPrimary(context.Background()), database.GetDB(), seed.Options{
+		Seed:       *seedValue,
+		Users:      *users,
+		Categories: *categories,
+		Tags:       *tags,
+		Articles:   *articles,
+		Comments:   *comments,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("示例数据生成完成（seed=%d）: 用户 %d，分类 %d，标签 %d，文章 %d，评论 %d\n",
+		*seedValue, result.Users, result.Categories, result.Tags, result.Articles, result.Comments)
+	fmt.Printf("所有用户的密码均为 %s，管理员账号为 admin\n", seed.DefaultPassword)
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/xiaoxin/blog-backend/internal/container"
+	"github.com/xiaoxin/blog-backend/internal/middleware"
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/routes"
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/cursor"
+	"github.com/xiaoxin/blog-backend/pkg/database"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+	"github.com/xiaoxin/blog-backend/pkg/redis"
+	"github.com/xiaoxin/blog-backend/pkg/tracing"
+)
+
+// runServe 启动HTTP服务
+func runServe(opts options, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server serve")
+	}
+	_ = fs.Parse(args)
+
+	// 1~3. 加载配置、初始化日志系统和数据库
+	cfg, cleanup, err := setup(opts)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	logger.Info("日志系统初始化完成")
+
+	// 初始化链路追踪
+	if err := tracing.InitTracer(&cfg.Tracing, cfg.App.Name, cfg.App.Version); err != nil {
+		return fmt.Errorf("初始化链路追踪失败: %w", err)
+	}
+	defer func() {
+		if err := tracing.Shutdown(); err != nil {
+			logger.Errorf("关闭链路追踪失败: %v", err)
+		}
+	}()
+
+	// 数据表迁移
+	if cfg.Database.AutoMigrate {
+		// 开发环境使用 AutoMigrate 快速同步模型结构
+		if err := database.AutoMigrate(
+			&models.User{},
+			&models.Category{},
+			&models.Tag{},
+			&models.Article{},
+			&models.Comment{},
+			&models.RecoveryCode{},
+			&models.UserIdentity{},
+		); err != nil {
+			return fmt.Errorf("数据表迁移失败: %w", err)
+		}
+		logger.Info("数据表 AutoMigrate 完成")
+	} else if cfg.Database.MigrateOnStart {
+		migrator, err := database.NewMigrator(database.GetDB())
+		if err != nil {
+			return fmt.Errorf("加载数据库迁移失败: %w", err)
+		}
+		if err := migrator.Up(context.Background()); err != nil {
+			return fmt.Errorf("数据表迁移失败: %w", err)
+		}
+		logger.Infof("数据表迁移完成，当前版本: %d", migrator.Latest())
+	}
+
+	// 4. 初始化Redis
+	if err := redis.InitRedis(&cfg.Redis); err != nil {
+		return fmt.Errorf("初始化Redis失败: %w", err)
+	}
+	defer redis.CloseRedis()
+
+	// 5. 初始化JWT
+	pkgjwt.InitJWT(cfg.JWT.Secret)
+	logger.Info("JWT初始化完成")
+
+	// 分页游标的签名密钥由 JWT 密钥派生，多实例间游标通用
+	cursor.Init(cfg.JWT.Secret)
+
+	// 初始化邮件服务
+	if err := mail.Init(&cfg.Mail); err != nil {
+		return fmt.Errorf("初始化邮件服务失败: %w", err)
+	}
+	logger.Info("邮件服务初始化完成", zap.String("driver", cfg.Mail.GetDriver()))
+
+	// 监听配置文件变化，热更新运行时可修改的配置
+	config.Watch(opts.configPath, opts.env, func(old, new *config.Config, changes, ignored []string) {
+		logger.SetLevel(new.Log.Level)
+		if len(changes) > 0 {
+			logger.Info("配置已热更新", zap.Strings("changes", changes))
+		}
+		if len(ignored) > 0 {
+			logger.Warn("以下配置变更需要重启服务才能生效", zap.Strings("ignored", ignored))
+		}
+	}, func(err error) {
+		logger.Error("配置热更新失败", zap.Error(err))
+	})
+
+	// 6. 设置Gin模式和默认语言
+	gin.SetMode(cfg.App.Mode)
+	if cfg.App.Locale != "" {
+		if err := i18n.SetDefault(cfg.App.Locale); err != nil {
+			return err
+		}
+	}
+
+	// 7. 创建路由引擎并组装依赖
+	r := gin.New()
+	// 只信任配置的反向代理，否则客户端可以伪造 X-Forwarded-For 绕过按IP的限流和登录保护
+	if err := r.SetTrustedProxies(cfg.App.TrustedProxies); err != nil {
+		return fmt.Errorf("设置可信代理失败: %w", err)
+	}
+	deps := container.NewWithDB(database.GetDB())
+	if err := validation.Init(); err != nil {
+		return fmt.Errorf("初始化参数校验失败: %w", err)
+	}
+
+	// 8. 使用中间件
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Logger())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Recovery())
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.CORS())
+	r.Use(middleware.Locale(deps.UserService))
+	r.Use(middleware.RateLimit())
+	r.Use(middleware.ReadYourWrites())
+
+	// 9. 设置路由
+	routes.SetupRoutes(r, deps)
+	if _, problems := routes.OpenAPI(r); len(problems) > 0 {
+		logger.Warn("接口文档不完整", zap.Strings("problems", problems))
+	}
+
+	// 10. 启动服务
+	addr := fmt.Sprintf(":%d", cfg.App.Port)
+	logger.Infof("服务启动成功，监听地址: %s", addr)
+	logger.Infof("应用名称: %s", cfg.App.Name)
+	logger.Infof("应用版本: %s", cfg.App.Version)
+	logger.Infof("运行模式: %s", cfg.App.Mode)
+
+	// 11. 优雅关闭
+	go func() {
+		if err := r.Run(addr); err != nil {
+			logger.Fatalf("服务启动失败: %v", err)
+		}
+	}()
+
+	// 12. 等待中断信号
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("正在关闭服务...")
+	logger.Info("服务已关闭")
+	return nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository/gormrepo"
+	"github.com/xiaoxin/blog-backend/internal/services"
+	"github.com/xiaoxin/blog-backend/pkg/database"
+)
+
+const userUsage = `用法: server user <命令> [参数]
+
+命令:
+  create --username 用户名 [--password 密码] [--email 邮箱] [--nickname 昵称] [--admin]
+                                      创建用户，--admin 创建管理员
+  set-role --username 用户名 --role <admin|user>
+                                      修改用户角色
+  reset-password --username 用户名 [--password 新密码]
+                                      重置密码
+  disable --username 用户名           禁用用户
+  enable --username 用户名            启用用户
+
+未指定 --password 时从标准输入读取密码。
+`
+
+// runUser 用户管理命令
+func runUser(opts options, args []string) error {
+	if len(args) < 1 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(os.Stderr, userUsage)
+		if len(args) < 1 {
+			return errors.New("缺少用户管理命令")
+		}
+		return nil
+	}
+
+	cmd, args := args[0], args[1:]
+	fs := flag.NewFlagSet("user "+cmd, flag.ExitOnError)
+	username := fs.String("username", "", "用户名")
+	password := fs.String("password", "", "密码，未指定时从标准输入读取")
+	email := fs.String("email", "", "邮箱")
+	nickname := fs.String("nickname", "", "昵称")
+	admin := fs.Bool("admin", false, "创建管理员")
+	role := fs.String("role", "", "角色: admin 或 user")
+	_ = fs.Parse(args)
+
+	if *username == "" {
+		fs.Usage()
+		return errors.New("请指定 --username")
+	}
+
+	_, cleanup, err := setup(opts)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// 管理命令始终读主库，避免副本复制延迟
+	ctx := database.WithPrimary(context.Background())
+	userService := services.NewUserService(gormrepo.NewUserRepository(database.GetDB()), nil, nil, nil)
+
+	switch cmd {
+	case "create":
+		pwd, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+
+		userRole := models.RoleUser
+		if *admin {
+			userRole = models.RoleAdmin
+		}
+
+		user, err := userService.CreateUser(ctx, *username, pwd, *email, *nickname, userRole)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("用户创建成功: id=%d username=%s role=%s\n", user.ID, user.Username, user.Role)
+		return nil
+	}
+
+	// 以下命令作用于已有用户
+	user, err := userService.GetUserByUsername(ctx, *username)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "set-role":
+		if err := userService.SetRole(ctx, user.ID, *role); err != nil {
+			return err
+		}
+		fmt.Printf("用户 %s 的角色已修改为 %s\n", user.Username, *role)
+	case "reset-password":
+		pwd, err := readPassword(*password)
+		if err != nil {
+			return err
+		}
+		if err := userService.ResetPassword(ctx, user.ID, pwd); err != nil {
+			return err
+		}
+		fmt.Printf("用户 %s 的密码已重置\n", user.Username)
+	case "disable":
+		if err := userService.BanUser(ctx, user.ID, "", nil); err != nil {
+			return err
+		}
+		fmt.Printf("用户 %s 已禁用\n", user.Username)
+	case "enable":
+		if err := userService.UnbanUser(ctx, user.ID); err != nil {
+			return err
+		}
+		fmt.Printf("用户 %s 已启用\n", user.Username)
+	default:
+		fmt.Fprint(os.Stderr, userUsage)
+		return fmt.Errorf("未知的用户管理命令: %s", cmd)
+	}
+	return nil
+}
+
+// readPassword 获取密码，未通过参数指定时从标准输入读取，终端输入时不回显
+func readPassword(password string) (string, error) {
+	if password == "" {
+		fd := int(os.Stdin.Fd())
+		if term.IsTerminal(fd) {
+			fmt.Fprint(os.Stderr, "请输入密码: ")
+			b, err := term.ReadPassword(fd)
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return "", fmt.Errorf("读取密码失败: %w", err)
+			}
+			password = string(b)
+		} else {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return "", fmt.Errorf("读取密码失败: %w", err)
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+	}
+
+	// 与注册接口的密码长度限制保持一致
+	if len(password) < 6 || len(password) > 50 {
+		return "", errors.New("密码长度必须在6~50个字符之间")
+	}
+	return password, nil
+}
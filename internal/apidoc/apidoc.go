@@ -0,0 +1,338 @@
+// Package apidoc 根据路由表和请求结构体生成 OpenAPI 3 文档，并提供离线的 Swagger UI
+//
+// 每个路由需要在文档表中登记一条 Route，请求体、查询参数和响应数据通过反射结构体生成，
+// binding 标签中的校验规则转换为 required、maxLength、enum 等约束。
+package apidoc
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files/v2"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/internal/validation"
+)
+
+// Auth 接口的认证要求
+type Auth int
+
+const (
+	AuthNone  Auth = iota // 公开接口
+	AuthUser              // 需要登录
+	AuthAdmin             // 需要管理员权限
+)
+
+// securityScheme 认证方式在 components 中的名称
+const securityScheme = "bearerAuth"
+
+// Route 单个路由的文档
+type Route struct {
+	Method      string
+	Path        string // gin 路由路径，如 /api/v1/articles/:id
+	Tag         string
+	Summary     string
+	Description string
+	Auth        Auth
+
+	Query  interface{} // 查询参数结构体，字段使用 form 标签
+	Body   interface{} // JSON 请求体结构体
+	Upload string      // multipart 上传的文件字段名
+
+	Data   interface{} // 响应中 data 字段的结构，为 nil 时不返回 data
+	Page   bool        // data 为分页结构，Data 为列表元素
+	Cursor bool        // data 为游标分页结构，Data 为列表元素
+	Errors []int       // 除通用错误外可能返回的状态码，如 409
+
+	Hidden bool // 不出现在文档中的路由，如静态文件
+}
+
+// 通用错误响应的说明
+var statusDescriptions = map[int]string{
+	http.StatusBadRequest:            "请求参数错误",
+	http.StatusUnauthorized:          "未登录或认证令牌无效",
+	http.StatusForbidden:             "权限不足",
+	http.StatusNotFound:              "资源不存在",
+	http.StatusConflict:              "资源冲突",
+	http.StatusRequestEntityTooLarge: "请求内容过大",
+	http.StatusTooManyRequests:       "请求过于频繁",
+	http.StatusBadGateway:            "依赖的外部服务不可用",
+}
+
+// Build 根据已注册的路由和文档表生成文档
+// 返回的 problems 列出缺少文档的路由和没有对应路由的文档
+func Build(info Info, routes gin.RoutesInfo, docs []Route) (*Document, []string) {
+	b := newSchemaBuilder(map[string]RuleFunc{
+		"username": func(s *Schema, _ string) {
+			s.Pattern = validation.UsernamePattern.String()
+		},
+		"password": func(s *Schema, _ string) {
+			s.Description = "必须同时包含字母和数字"
+		},
+	})
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	// 统一响应结构，details 为字段校验错误列表
+	b.schema(reflect.TypeOf(utils.Response{}))
+	b.schemas["Response"].Properties["details"] = &Schema{
+		Type:        "array",
+		Description: "错误详情，参数校验失败时为各字段的错误",
+		Items:       b.schema(reflect.TypeOf(validation.FieldError{})),
+	}
+
+	byKey := make(map[string]Route, len(docs))
+	for _, d := range docs {
+		byKey[d.Method+" "+d.Path] = d
+	}
+
+	var problems []string
+	seen := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		key := r.Method + " " + r.Path
+		seen[key] = true
+
+		d, ok := byKey[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("路由 %s 缺少接口文档", key))
+			continue
+		}
+		if d.Hidden {
+			continue
+		}
+
+		path, params := convertPath(r.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(r.Method)] = b.operation(d, params, operationID(r.Handler))
+	}
+
+	for _, d := range docs {
+		if key := d.Method + " " + d.Path; !seen[key] && !d.Hidden {
+			problems = append(problems, fmt.Sprintf("接口文档 %s 没有对应的路由", key))
+		}
+	}
+
+	doc.Components.Schemas = b.schemas
+	sort.Strings(problems)
+	return doc, problems
+}
+
+// operation 生成单个接口
+func (b *schemaBuilder) operation(d Route, pathParams []string, id string) *Operation {
+	op := &Operation{
+		Summary:     d.Summary,
+		Description: d.Description,
+		OperationID: id,
+		Responses:   make(map[string]*Response),
+	}
+	if d.Tag != "" {
+		op.Tags = []string{d.Tag}
+	}
+
+	for _, name := range pathParams {
+		s := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			s = &Schema{Type: "integer"}
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: s})
+	}
+	if d.Query != nil {
+		op.Parameters = append(op.Parameters, b.queryParams(reflect.TypeOf(d.Query))...)
+	}
+
+	switch {
+	case d.Body != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: b.schema(reflect.TypeOf(d.Body))}},
+		}
+	case d.Upload != "":
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{d.Upload: {Type: "string", Format: "binary"}},
+				Required:   []string{d.Upload},
+			}}},
+		}
+	}
+
+	// 成功响应
+	var data *Schema
+	if d.Data != nil {
+		data = b.schema(reflect.TypeOf(d.Data))
+		switch {
+		case d.Page:
+			data = pageOf(b.schema(reflect.TypeOf(utils.PageData{})), data)
+		case d.Cursor:
+			data = pageOf(b.schema(reflect.TypeOf(utils.CursorPageData{})), data)
+		}
+	}
+	op.Responses["200"] = &Response{Description: "成功", Content: jsonContent(envelope(data))}
+
+	// 错误响应
+	statuses := append([]int(nil), d.Errors...)
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		statuses = append(statuses, http.StatusBadRequest)
+	}
+	if d.Auth >= AuthUser {
+		statuses = append(statuses, http.StatusUnauthorized)
+		op.Security = []map[string][]string{{securityScheme: {}}}
+	}
+	if d.Auth == AuthAdmin {
+		statuses = append(statuses, http.StatusForbidden)
+	}
+	if len(pathParams) > 0 {
+		statuses = append(statuses, http.StatusNotFound)
+	}
+	for _, status := range statuses {
+		desc, ok := statusDescriptions[status]
+		if !ok {
+			desc = http.StatusText(status)
+		}
+		op.Responses[fmt.Sprint(status)] = &Response{Description: desc, Content: jsonContent(ref("Response"))}
+	}
+	op.Responses["default"] = &Response{Description: "服务器内部错误", Content: jsonContent(ref("Response"))}
+
+	return op
+}
+
+// queryParams 根据结构体的 form 标签生成查询参数
+func (b *schemaBuilder) queryParams(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("form")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+
+		s := b.schema(field.Type)
+		for _, opt := range strings.Split(tag, ",")[1:] {
+			if v, ok := strings.CutPrefix(opt, "default="); ok {
+				s.Default = v
+				if n, err := strconv.Atoi(v); err == nil && s.Type == "integer" {
+					s.Default = n
+				}
+			}
+		}
+
+		required := false
+		if binding := field.Tag.Get("binding"); binding != "" {
+			required = b.applyRules(s, binding)
+		}
+		params = append(params, Parameter{
+			Name:     tagName(tag, field.Name),
+			In:       "query",
+			Required: required,
+			Schema:   s,
+		})
+	}
+	return params
+}
+
+// pageOf 将列表元素填入分页结构的 list 字段
+func pageOf(page, item *Schema) *Schema {
+	return &Schema{AllOf: []*Schema{
+		page,
+		{Type: "object", Properties: map[string]*Schema{"list": {Type: "array", Items: item}}},
+	}}
+}
+
+// envelope 将 data 包装在统一响应结构中
+func envelope(data *Schema) *Schema {
+	if data == nil {
+		return ref("Response")
+	}
+	return &Schema{AllOf: []*Schema{
+		ref("Response"),
+		{Type: "object", Properties: map[string]*Schema{"data": data}},
+	}}
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// convertPath 将 gin 路径转换为 OpenAPI 路径，返回路径参数名
+// 如 /articles/:id -> /articles/{id}
+func convertPath(path string) (string, []string) {
+	var params []string
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if len(part) > 1 && (part[0] == ':' || part[0] == '*') {
+			params = append(params, part[1:])
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/"), params
+}
+
+// operationID 使用处理函数名作为接口ID
+// 如 github.com/.../controllers.(*UserController).Register-fm -> Register
+func operationID(handler string) string {
+	handler = strings.TrimSuffix(handler, "-fm")
+	return handler[strings.LastIndex(handler, ".")+1:]
+}
+
+// SpecHandler 返回文档的处理函数
+func SpecHandler(doc *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// swaggerInitializer 替换 Swagger UI 默认的初始化脚本，加载本服务的文档
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// UIHandler 返回 Swagger UI 的处理函数，路由需要以 /*filepath 结尾
+// 静态资源编译进二进制，无需访问外网
+func UIHandler(specURL string) gin.HandlerFunc {
+	initializer := fmt.Sprintf(swaggerInitializer, specURL)
+	fs := http.FS(swaggerfiles.FS)
+
+	return func(c *gin.Context) {
+		file := c.Param("filepath")
+		if file == "/swagger-initializer.js" {
+			c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(initializer))
+			return
+		}
+		c.FileFromFS(file, fs)
+	}
+}
@@ -0,0 +1,224 @@
+package apidoc
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuleFunc 将自定义校验规则转换为文档描述
+type RuleFunc func(s *Schema, param string)
+
+// schemaBuilder 通过反射生成数据结构，具名结构体放入 components 并以引用代替
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	rules   map[string]RuleFunc
+}
+
+func newSchemaBuilder(rules map[string]RuleFunc) *schemaBuilder {
+	return &schemaBuilder{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+		rules:   rules,
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schema 生成类型对应的数据结构
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return ref(b.component(t))
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	}
+
+	// interface{} 等任意类型
+	return &Schema{}
+}
+
+// component 将具名结构体放入 components，返回其名称
+// 先登记名称再生成字段，以支持 Article -> User -> Article 这样的循环引用
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, exists := b.schemas[name]; exists {
+		// 不同包中的同名结构体加上包名区分
+		name = pkgName(t) + name
+	}
+	b.names[t] = name
+	b.schemas[name] = &Schema{}
+	*b.schemas[name] = *b.object(t)
+	return name
+}
+
+// object 生成结构体的字段，匿名嵌入且没有 json 标签的结构体字段会被展开
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.fields(t, s)
+	return s
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, s)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tagName(tag, field.Name)
+		if name == "-" {
+			continue
+		}
+
+		prop := b.schema(field.Type)
+		if binding := field.Tag.Get("binding"); binding != "" {
+			// 引用不能附带其他属性，校验规则放在 allOf 外层
+			if prop.Ref != "" {
+				prop = &Schema{AllOf: []*Schema{prop}}
+			}
+			if b.applyRules(prop, binding) {
+				s.Required = append(s.Required, name)
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// applyRules 将 binding 校验规则转换为文档约束，返回字段是否必填
+// 无法转换的规则保留在 x-binding 中
+func (b *schemaBuilder) applyRules(s *Schema, binding string) bool {
+	s.Binding = binding
+
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "dive" {
+			// dive 之后的规则作用于元素
+			break
+		}
+
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			applyBound(s, name, param)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				if s.Type == "integer" {
+					if n, err := strconv.Atoi(v); err == nil {
+						s.Enum = append(s.Enum, n)
+						continue
+					}
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		default:
+			if fn, ok := b.rules[name]; ok {
+				fn(s, param)
+			}
+		}
+	}
+	return required
+}
+
+// applyBound 按字段类型转换长度或大小限制
+func applyBound(s *Schema, rule, param string) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	i := int(n)
+
+	switch s.Type {
+	case "string":
+		if rule != "max" {
+			s.MinLength = &i
+		}
+		if rule != "min" {
+			s.MaxLength = &i
+		}
+	case "array":
+		if rule != "max" {
+			s.MinItems = &i
+		}
+		if rule != "min" {
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		if rule != "max" {
+			s.Minimum = &n
+		}
+		if rule != "min" {
+			s.Maximum = &n
+		}
+	}
+}
+
+// tagName 获取 json/form 标签中的字段名，未设置时使用结构体字段名
+func tagName(tag, fallback string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+// pkgName 类型所在包的名称，首字母大写
+func pkgName(t reflect.Type) string {
+	path := t.PkgPath()
+	name := path[strings.LastIndex(path, "/")+1:]
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
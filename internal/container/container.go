@@ -0,0 +1,58 @@
+// Package container 组装仓储、服务和控制器
+package container
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/controllers"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/repository/gormrepo"
+	"github.com/xiaoxin/blog-backend/internal/repository/redisrepo"
+	"github.com/xiaoxin/blog-backend/internal/services"
+	"github.com/xiaoxin/blog-backend/pkg/redis"
+)
+
+// Container 依赖容器，持有应用的全部服务和控制器
+type Container struct {
+	Repositories *repository.Repositories
+
+	UserService     *services.UserService
+	ArticleService  *services.ArticleService
+	CategoryService *services.CategoryService
+	CommentService  *services.CommentService
+	OIDCService     *services.OIDCService
+
+	UserController      *controllers.UserController
+	ArticleController   *controllers.ArticleController
+	CategoryController  *controllers.CategoryController
+	UploadController    *controllers.UploadController
+	AdminUserController *controllers.AdminUserController
+	OIDCController      *controllers.OIDCController
+}
+
+// New 基于给定的仓储创建容器，测试时可传入 memory.NewRepositories()
+func New(repos *repository.Repositories) *Container {
+	c := &Container{Repositories: repos}
+
+	// 服务
+	c.UserService = services.NewUserService(repos.Users, repos.RecoveryCodes, repos.PasswordResets, repos.LoginAttempts)
+	c.ArticleService = services.NewArticleService(repos.Articles, repos.UnitOfWork)
+	c.CategoryService = services.NewCategoryService(repos.Categories, repos.Articles)
+	c.CommentService = services.NewCommentService(repos.Comments, repos.Users)
+	c.OIDCService = services.NewOIDCService(c.UserService, repos.Identities, repos.OAuthStates, repos.UnitOfWork)
+
+	// 控制器
+	c.UserController = controllers.NewUserController(c.UserService)
+	c.ArticleController = controllers.NewArticleController(c.ArticleService)
+	c.CategoryController = controllers.NewCategoryController(c.CategoryService)
+	c.UploadController = controllers.NewUploadController()
+	c.AdminUserController = controllers.NewAdminUserController(c.UserService, c.ArticleService, c.CommentService)
+	c.OIDCController = controllers.NewOIDCController(c.OIDCService)
+
+	return c
+}
+
+// NewWithDB 创建基于数据库和 Redis 的容器，需先初始化 Redis
+func NewWithDB(db *gorm.DB) *Container {
+	return New(redisrepo.Attach(gormrepo.NewRepositories(db), redis.GetClient()))
+}
@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/services"
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/internal/views"
+)
+
+// AdminUserController 管理员用户管理控制器
+type AdminUserController struct {
+	userService    *services.UserService
+	articleService *services.ArticleService
+	commentService *services.CommentService
+}
+
+// NewAdminUserController 创建管理员用户管理控制器实例
+func NewAdminUserController(userService *services.UserService, articleService *services.ArticleService, commentService *services.CommentService) *AdminUserController {
+	return &AdminUserController{
+		userService:    userService,
+		articleService: articleService,
+		commentService: commentService,
+	}
+}
+
+// UserSearchQuery 用户搜索参数，用户名和邮箱为模糊匹配
+type UserSearchQuery struct {
+	Username string `form:"username" binding:"max=50"`
+	Email    string `form:"email" binding:"max=100"`
+	Role     string `form:"role" binding:"omitempty,oneof=admin user"`
+	Status   *int   `form:"status" binding:"omitempty,oneof=0 1"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// SearchUsers 分页搜索用户
+func (ctrl *AdminUserController) SearchUsers(c *gin.Context) {
+	var query UserSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	users, total, err := ctrl.userService.SearchUsers(c.Request.Context(), repository.UserFilter{
+		Username: query.Username,
+		Email:    query.Email,
+		Role:     query.Role,
+		Status:   query.Status,
+	}, query.Page, query.PageSize)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.PageSuccess(c, users, total, query.Page, query.PageSize)
+}
+
+// BanUserRequest 封禁用户请求
+type BanUserRequest struct {
+	Reason string     `json:"reason" binding:"required,max=255"`
+	Until  *time.Time `json:"until"` // 封禁到期时间，RFC3339 格式，为空表示永久封禁
+}
+
+// BanUser 封禁用户
+func (ctrl *AdminUserController) BanUser(c *gin.Context) {
+	id, ok := ctrl.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if err := ctrl.userService.BanUser(c.Request.Context(), id, req.Reason, req.Until); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "update_success", nil)
+}
+
+// UnbanUser 解除封禁
+func (ctrl *AdminUserController) UnbanUser(c *gin.Context) {
+	id, ok := ctrl.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.userService.UnbanUser(c.Request.Context(), id); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "update_success", nil)
+}
+
+// ChangeRoleRequest 修改角色请求
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin user"`
+}
+
+// ChangeRole 修改用户角色
+func (ctrl *AdminUserController) ChangeRole(c *gin.Context) {
+	id, ok := ctrl.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if err := ctrl.userService.SetRole(c.Request.Context(), id, req.Role); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "update_success", nil)
+}
+
+// ForceLogout 强制用户下线
+func (ctrl *AdminUserController) ForceLogout(c *gin.Context) {
+	id, ok := ctrl.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.userService.ForceLogout(c.Request.Context(), id); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "logout_success", nil)
+}
+
+// ResetTwoFactor 重置用户的两步验证，用于用户丢失验证设备且没有恢复码的情况
+func (ctrl *AdminUserController) ResetTwoFactor(c *gin.Context) {
+	id, ok := ctrl.targetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.userService.ResetTwoFactor(c.Request.Context(), id); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "two_factor_reset", nil)
+}
+
+// ListLoginLocks 获取因登录失败次数过多被锁定的用户名
+func (ctrl *AdminUserController) ListLoginLocks(c *gin.Context) {
+	locks, err := ctrl.userService.ListLoginLocks(c.Request.Context())
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, locks)
+}
+
+// UnlockLogin 解除用户名的登录锁定
+func (ctrl *AdminUserController) UnlockLogin(c *gin.Context) {
+	if err := ctrl.userService.UnlockLogin(c.Request.Context(), c.Param("username")); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "login_unlocked", nil)
+}
+
+// PageQuery 页码分页参数
+type PageQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+// ListUserArticles 获取用户的文章，包含草稿
+func (ctrl *AdminUserController) ListUserArticles(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var query PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if _, err := ctrl.userService.GetUserByID(c.Request.Context(), id); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	q := services.ArticleListQuery{PageSize: query.PageSize, WithStatus: true}
+	q.AuthorID = &id
+	articles, total, err := ctrl.articleService.GetArticleList(c.Request.Context(), q, query.Page)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.PageSuccess(c, views.NewArticleOwnerList(articles), total, query.Page, query.PageSize)
+}
+
+// ListUserComments 获取用户的评论
+func (ctrl *AdminUserController) ListUserComments(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var query PageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	comments, total, err := ctrl.commentService.ListUserComments(c.Request.Context(), id, query.Page, query.PageSize)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.PageSuccess(c, views.NewCommentList(comments), total, query.Page, query.PageSize)
+}
+
+// targetUserID 解析被操作的用户ID，管理员不能对自己执行封禁、改角色等操作，避免误操作后无法恢复
+func (ctrl *AdminUserController) targetUserID(c *gin.Context) (uint, bool) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return 0, false
+	}
+
+	if currentID, _ := c.Get("user_id"); currentID == id {
+		utils.Fail(c, services.ErrCannotModifySelf)
+		return 0, false
+	}
+	return id, true
+}
+
+// parseUserID 解析路径中的用户ID，失败时直接响应错误
+func parseUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequest(c, "invalid_user_id")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -2,12 +2,17 @@ package controllers
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	"github.com/xiaoxin/blog-backend/internal/models"
 	"github.com/xiaoxin/blog-backend/internal/services"
 	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/internal/views"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/query"
 )
 
 // ArticleController 文章控制器
@@ -16,12 +21,17 @@ type ArticleController struct {
 }
 
 // NewArticleController 创建文章控制器实例
-func NewArticleController() *ArticleController {
+func NewArticleController(articleService *services.ArticleService) *ArticleController {
 	return &ArticleController{
-		articleService: services.NewArticleService(),
+		articleService: articleService,
 	}
 }
 
+// CreatedResponse 创建成功响应
+type CreatedResponse struct {
+	ID uint `json:"id"`
+}
+
 // CreateArticleRequest 创建文章请求
 type CreateArticleRequest struct {
 	Title       string `json:"title" binding:"required,max=200"`
@@ -38,13 +48,13 @@ type CreateArticleRequest struct {
 func (ctrl *ArticleController) CreateArticle(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		utils.Unauthorized(c, "未授权")
+		utils.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var req CreateArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
@@ -66,12 +76,12 @@ func (ctrl *ArticleController) CreateArticle(c *gin.Context) {
 		IsTop:       req.IsTop,
 	}
 
-	if err := ctrl.articleService.CreateArticle(article); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.articleService.CreateArticle(c.Request.Context(), article); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "创建成功", gin.H{"id": article.ID})
+	utils.SuccessWithMsg(c, "create_success", CreatedResponse{ID: article.ID})
 }
 
 // GetArticle 获取文章详情
@@ -79,47 +89,123 @@ func (ctrl *ArticleController) GetArticle(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的文章ID")
+		utils.BadRequest(c, "invalid_article_id")
 		return
 	}
 
-	article, err := ctrl.articleService.GetArticleByID(uint(id))
+	article, err := ctrl.articleService.GetArticleByID(c.Request.Context(), uint(id))
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	// 增加浏览量，失败不影响返回文章
+	if err := ctrl.articleService.IncrementViewCount(c.Request.Context(), uint(id)); err != nil {
+		logger.WithContext(c.Request.Context()).Warn("增加浏览量失败", zap.Uint64("article_id", id), zap.Error(err))
+	}
+
+	// 作者本人和管理员可以看到发布状态
+	userID, _ := c.Get("user_id")
+	if role, _ := c.Get("role"); role == models.RoleAdmin || (userID != nil && userID.(uint) == article.AuthorID) {
+		utils.Success(c, views.NewArticleOwnerView(article))
 		return
 	}
+	utils.Success(c, views.NewArticleDetail(article))
+}
 
-	// 增加浏览量
-	_ = ctrl.articleService.IncrementViewCount(uint(id))
+// ArticleListQuery 文章列表查询参数
+// 默认使用游标分页；传入 page 且不传 cursor 时按页码分页并返回总数，兼容旧客户端
+type ArticleListQuery struct {
+	Cursor      string `form:"cursor"`
+	PageSize    int    `form:"page_size,default=10" binding:"min=1,max=100"`
+	WithTotal   bool   `form:"with_total"`
+	Page        int    `form:"page" binding:"omitempty,min=1"` // 已废弃，请使用 cursor
+	Status      *int   `form:"status" binding:"omitempty,oneof=0 1"`
+	CategoryID  *uint  `form:"category_id"`
+	AuthorID    *uint  `form:"author_id"`
+	Tags        string `form:"tags"`                                       // 标签ID，逗号分隔
+	TagMode     string `form:"tag_mode" binding:"omitempty,oneof=any all"` // any: 包含任一标签（默认），all: 包含全部标签
+	Keyword     string `form:"keyword" binding:"max=100"`                  // 标题关键字
+	CreatedFrom string `form:"created_from"`                               // RFC3339 或 2006-01-02，下同
+	CreatedTo   string `form:"created_to"`
+	UpdatedFrom string `form:"updated_from"`
+	UpdatedTo   string `form:"updated_to"`
+	Sort        string `form:"sort"`   // created_at、updated_at、views、likes、comments，"-" 前缀表示倒序
+	Fields      string `form:"fields"` // 返回的字段，逗号分隔
+}
 
-	utils.Success(c, article)
+// parse 解析需要白名单校验的参数
+func (q *ArticleListQuery) parse() (services.ArticleListQuery, error) {
+	var p query.Parser
+	result := services.ArticleListQuery{
+		Fields:    p.Fields("fields", q.Fields, services.ArticleFields),
+		Cursor:    q.Cursor,
+		PageSize:  q.PageSize,
+		WithTotal: q.WithTotal,
+	}
+	result.Status = q.Status
+	result.CategoryID = q.CategoryID
+	result.AuthorID = q.AuthorID
+	result.TagIDs = p.Uints("tags", q.Tags)
+	result.MatchAllTags = q.TagMode == "all"
+	result.Keyword = strings.TrimSpace(q.Keyword)
+	result.CreatedFrom = p.Time("created_from", q.CreatedFrom, false)
+	result.CreatedTo = p.Time("created_to", q.CreatedTo, true)
+	result.UpdatedFrom = p.Time("updated_from", q.UpdatedFrom, false)
+	result.UpdatedTo = p.Time("updated_to", q.UpdatedTo, true)
+	if orders := p.Sort("sort", q.Sort, services.ArticleSorts, 1); len(orders) > 0 {
+		result.Sort = orders[0]
+	}
+	return result, p.Err()
 }
 
 // GetArticleList 获取文章列表
 func (ctrl *ArticleController) GetArticleList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	var status *int
-	if statusStr := c.Query("status"); statusStr != "" {
-		s, _ := strconv.Atoi(statusStr)
-		status = &s
+	var req ArticleListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+	q, err := req.parse()
+	if err != nil {
+		utils.InvalidParams(c, err)
+		return
 	}
 
-	var categoryID *uint
-	if categoryIDStr := c.Query("category_id"); categoryIDStr != "" {
-		cid, _ := strconv.ParseUint(categoryIDStr, 10, 32)
-		catID := uint(cid)
-		categoryID = &catID
+	if req.Page > 0 && req.Cursor == "" {
+		articles, total, err := ctrl.articleService.GetArticleList(c.Request.Context(), q, req.Page)
+		if err != nil {
+			utils.AbortWithError(c, err)
+			return
+		}
+
+		list, err := query.Pick(views.NewArticleList(articles), q.Fields)
+		if err != nil {
+			utils.AbortWithError(c, err)
+			return
+		}
+		utils.PageSuccess(c, list, total, req.Page, req.PageSize)
+		return
 	}
 
-	articles, total, err := ctrl.articleService.GetArticleList(page, pageSize, status, categoryID)
+	result, err := ctrl.articleService.ListArticles(c.Request.Context(), q)
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.PageSuccess(c, articles, total, page, pageSize)
+	list, err := query.Pick(views.NewArticleList(result.Articles), q.Fields)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+	utils.CursorPageSuccess(c, utils.CursorPageData{
+		List:       list,
+		Size:       req.PageSize,
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
+		Total:      result.Total,
+	})
 }
 
 // UpdateArticleRequest 更新文章请求
@@ -139,13 +225,13 @@ func (ctrl *ArticleController) UpdateArticle(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的文章ID")
+		utils.BadRequest(c, "invalid_article_id")
 		return
 	}
 
 	var req UpdateArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
@@ -166,12 +252,12 @@ func (ctrl *ArticleController) UpdateArticle(c *gin.Context) {
 		IsTop:       req.IsTop,
 	}
 
-	if err := ctrl.articleService.UpdateArticle(uint(id), article); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.articleService.UpdateArticle(c.Request.Context(), uint(id), article); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "更新成功", nil)
+	utils.SuccessWithMsg(c, "update_success", nil)
 }
 
 // DeleteArticle 删除文章
@@ -179,16 +265,16 @@ func (ctrl *ArticleController) DeleteArticle(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的文章ID")
+		utils.BadRequest(c, "invalid_article_id")
 		return
 	}
 
-	if err := ctrl.articleService.DeleteArticle(uint(id)); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.articleService.DeleteArticle(c.Request.Context(), uint(id)); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "删除成功", nil)
+	utils.SuccessWithMsg(c, "delete_success", nil)
 }
 
 // LikeArticle 点赞文章
@@ -196,14 +282,14 @@ func (ctrl *ArticleController) LikeArticle(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的文章ID")
+		utils.BadRequest(c, "invalid_article_id")
 		return
 	}
 
-	if err := ctrl.articleService.IncrementLikeCount(uint(id)); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.articleService.IncrementLikeCount(c.Request.Context(), uint(id)); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "点赞成功", nil)
+	utils.SuccessWithMsg(c, "like_success", nil)
 }
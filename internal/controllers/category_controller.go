@@ -15,9 +15,9 @@ type CategoryController struct {
 }
 
 // NewCategoryController 创建分类控制器实例
-func NewCategoryController() *CategoryController {
+func NewCategoryController(categoryService *services.CategoryService) *CategoryController {
 	return &CategoryController{
-		categoryService: services.NewCategoryService(),
+		categoryService: categoryService,
 	}
 }
 
@@ -32,17 +32,17 @@ type CreateCategoryRequest struct {
 func (ctrl *CategoryController) CreateCategory(c *gin.Context) {
 	var req CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
-	category, err := ctrl.categoryService.CreateCategory(req.Name, req.Description, req.Sort)
+	category, err := ctrl.categoryService.CreateCategory(c.Request.Context(), req.Name, req.Description, req.Sort)
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "创建成功", category)
+	utils.SuccessWithMsg(c, "create_success", category)
 }
 
 // GetCategory 获取分类详情
@@ -50,13 +50,13 @@ func (ctrl *CategoryController) GetCategory(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的分类ID")
+		utils.BadRequest(c, "invalid_category_id")
 		return
 	}
 
-	category, err := ctrl.categoryService.GetCategoryByID(uint(id))
+	category, err := ctrl.categoryService.GetCategoryByID(c.Request.Context(), uint(id))
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
@@ -65,9 +65,9 @@ func (ctrl *CategoryController) GetCategory(c *gin.Context) {
 
 // GetCategoryList 获取分类列表
 func (ctrl *CategoryController) GetCategoryList(c *gin.Context) {
-	categories, err := ctrl.categoryService.GetCategoryList()
+	categories, err := ctrl.categoryService.GetCategoryList(c.Request.Context())
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
@@ -86,22 +86,22 @@ func (ctrl *CategoryController) UpdateCategory(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的分类ID")
+		utils.BadRequest(c, "invalid_category_id")
 		return
 	}
 
 	var req UpdateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
-	if err := ctrl.categoryService.UpdateCategory(uint(id), req.Name, req.Description, req.Sort); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.categoryService.UpdateCategory(c.Request.Context(), uint(id), req.Name, req.Description, req.Sort); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "更新成功", nil)
+	utils.SuccessWithMsg(c, "update_success", nil)
 }
 
 // DeleteCategory 删除分类
@@ -109,14 +109,14 @@ func (ctrl *CategoryController) DeleteCategory(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的分类ID")
+		utils.BadRequest(c, "invalid_category_id")
 		return
 	}
 
-	if err := ctrl.categoryService.DeleteCategory(uint(id)); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.categoryService.DeleteCategory(c.Request.Context(), uint(id)); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "删除成功", nil)
+	utils.SuccessWithMsg(c, "delete_success", nil)
 }
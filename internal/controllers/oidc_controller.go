@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/services"
+	"github.com/xiaoxin/blog-backend/internal/utils"
+)
+
+// OIDCController 第三方登录控制器
+type OIDCController struct {
+	oidcService *services.OIDCService
+}
+
+// NewOIDCController 创建第三方登录控制器实例
+func NewOIDCController(oidcService *services.OIDCService) *OIDCController {
+	return &OIDCController{oidcService: oidcService}
+}
+
+// AuthURLResponse 授权页面地址
+type AuthURLResponse struct {
+	AuthURL string `json:"auth_url"` // 前端跳转到该地址，用户授权后身份提供方带 code 和 state 跳回 redirect_url
+}
+
+// OAuthCallbackRequest 授权回调参数，取自身份提供方跳回前端页面时的查询参数
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required,max=2048"`
+	State string `json:"state" binding:"required,max=100"`
+}
+
+// ListProviders 获取可用的第三方登录方式
+func (ctrl *OIDCController) ListProviders(c *gin.Context) {
+	utils.Success(c, ctrl.oidcService.Providers())
+}
+
+// Authorize 创建第三方登录授权请求
+func (ctrl *OIDCController) Authorize(c *gin.Context) {
+	url, err := ctrl.oidcService.AuthURL(c.Request.Context(), c.Param("provider"), 0)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, AuthURLResponse{AuthURL: url})
+}
+
+// Callback 完成第三方登录，返回本站令牌
+func (ctrl *OIDCController) Callback(c *gin.Context) {
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	result, err := ctrl.oidcService.Login(c.Request.Context(), c.Param("provider"), req.Code, req.State)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, newLoginResponse(result))
+}
+
+// ListIdentities 获取当前用户关联的第三方账号
+func (ctrl *OIDCController) ListIdentities(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	identities, err := ctrl.oidcService.ListIdentities(c.Request.Context(), userID.(uint))
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, identities)
+}
+
+// AuthorizeLink 创建关联第三方账号的授权请求
+func (ctrl *OIDCController) AuthorizeLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	url, err := ctrl.oidcService.AuthURL(c.Request.Context(), c.Param("provider"), userID.(uint))
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, AuthURLResponse{AuthURL: url})
+}
+
+// LinkIdentity 完成关联第三方账号
+func (ctrl *OIDCController) LinkIdentity(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	identity, err := ctrl.oidcService.Link(c.Request.Context(), userID.(uint), c.Param("provider"), req.Code, req.State)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "identity_linked", identity)
+}
+
+// UnlinkIdentity 解除关联第三方账号
+func (ctrl *OIDCController) UnlinkIdentity(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := ctrl.oidcService.Unlink(c.Request.Context(), userID.(uint), c.Param("provider")); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "identity_unlinked", nil)
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/services"
+	"github.com/xiaoxin/blog-backend/internal/utils"
+)
+
+// LoginTwoFactorRequest 登录第二步请求，code 为六位动态码或恢复码
+type LoginTwoFactorRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+// LoginTwoFactor 验证动态码完成登录
+func (ctrl *UserController) LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	result, err := ctrl.userService.VerifyLoginTwoFactor(c.Request.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, newLoginResponse(result))
+}
+
+// TwoFactorStatusResponse 两步验证状态
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+	Required               bool       `json:"required"` // 当前角色是否必须启用
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+func (ctrl *UserController) GetTwoFactorStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	status, err := ctrl.userService.GetTwoFactorStatus(c.Request.Context(), userID.(uint))
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, TwoFactorStatusResponse{
+		Enabled:                status.Enabled,
+		EnabledAt:              status.EnabledAt,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+		Required:               status.Required,
+	})
+}
+
+// TwoFactorSetupResponse 两步验证绑定信息
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`      // 无法扫码时手动输入的密钥
+	OTPAuthURL string `json:"otpauth_url"` // otpauth:// 地址
+	QRCode     string `json:"qr_code"`     // 二维码图片，data:image/png;base64 格式
+}
+
+// SetupTwoFactor 生成两步验证密钥和二维码
+func (ctrl *UserController) SetupTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	setup, err := ctrl.userService.SetupTwoFactor(c.Request.Context(), userID.(uint))
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.Success(c, TwoFactorSetupResponse{
+		Secret:     setup.Secret,
+		OTPAuthURL: setup.URL,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(setup.QRCode),
+	})
+}
+
+// TwoFactorCodeRequest 动态码请求
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+}
+
+// RecoveryCodesResponse 恢复码，只在生成时返回一次
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// EnableTwoFactor 确认绑定并启用两步验证
+func (ctrl *UserController) EnableTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	codes, err := ctrl.userService.EnableTwoFactor(c.Request.Context(), userID.(uint), req.Code)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "two_factor_enabled", RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求，code 为动态码或恢复码
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"`
+}
+
+// DisableTwoFactor 关闭两步验证
+func (ctrl *UserController) DisableTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if err := ctrl.userService.DisableTwoFactor(c.Request.Context(), userID.(uint), req.Password, req.Code); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "two_factor_disabled", nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (ctrl *UserController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	codes, err := ctrl.userService.RegenerateRecoveryCodes(c.Request.Context(), userID.(uint), req.Code)
+	if err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "recovery_codes_regenerated", RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// newLoginResponse 根据登录结果生成响应
+func newLoginResponse(result *services.LoginResult) LoginResponse {
+	if result.MFAToken != "" {
+		return LoginResponse{MFARequired: true, MFAToken: result.MFAToken}
+	}
+	return LoginResponse{Token: result.Token, RefreshToken: result.RefreshToken}
+}
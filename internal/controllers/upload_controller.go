@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
 	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
 )
 
 // UploadController 文件上传控制器
@@ -14,26 +17,36 @@ func NewUploadController() *UploadController {
 	return &UploadController{}
 }
 
+// UploadResponse 上传响应
+type UploadResponse struct {
+	Path string `json:"path"` // 相对上传目录的路径
+	URL  string `json:"url"`  // 访问地址
+}
+
 // UploadFile 上传文件
 func (ctrl *UploadController) UploadFile(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
-		utils.BadRequest(c, "请选择要上传的文件")
+		utils.BadRequest(c, "file_required")
 		return
 	}
 
 	// 保存文件
 	relativePath, err := utils.SaveUploadedFile(file)
 	if err != nil {
-		utils.Error(c, err.Error())
+		metrics.FileUploads.WithLabelValues("failure").Inc()
+		utils.AbortWithError(c, err)
 		return
 	}
 
+	metrics.FileUploads.WithLabelValues("success").Inc()
+	logger.WithContext(c.Request.Context()).Info("文件上传成功", zap.String("path", relativePath), zap.Int64("size", file.Size))
+
 	// 获取文件访问URL
 	fileURL := utils.GetFileURL(relativePath)
 
-	utils.Success(c, gin.H{
-		"path": relativePath,
-		"url":  fileURL,
+	utils.Success(c, UploadResponse{
+		Path: relativePath,
+		URL:  fileURL,
 	})
 }
@@ -15,37 +15,43 @@ type UserController struct {
 }
 
 // NewUserController 创建用户控制器实例
-func NewUserController() *UserController {
+func NewUserController(userService *services.UserService) *UserController {
 	return &UserController{
-		userService: services.NewUserService(),
+		userService: userService,
 	}
 }
 
 // RegisterRequest 注册请求
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"`
-	Password string `json:"password" binding:"required,min=6,max=50"`
-	Email    string `json:"email" binding:"omitempty,email"`
+	Username string `json:"username" binding:"required,min=3,max=50,username"`
+	Password string `json:"password" binding:"required,min=6,max=50,password"`
+	Email    string `json:"email" binding:"omitempty,email,max=100"` // 注册后发送验证邮件
 	Nickname string `json:"nickname" binding:"max=50"`
 }
 
+// RegisterResponse 注册响应
+type RegisterResponse struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
 // Register 用户注册
 func (ctrl *UserController) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
-	user, err := ctrl.userService.Register(req.Username, req.Password, req.Email, req.Nickname)
+	user, err := ctrl.userService.Register(c.Request.Context(), req.Username, req.Password, req.Email, req.Nickname)
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "注册成功", gin.H{
-		"id":       user.ID,
-		"username": user.Username,
+	utils.SuccessWithMsg(c, "register_success", RegisterResponse{
+		ID:       user.ID,
+		Username: user.Username,
 	})
 }
 
@@ -55,37 +61,43 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse 登录响应
+// 已启用两步验证时只返回 mfa_required 和 mfa_token，需调用 /login/2fa 完成登录
+type LoginResponse struct {
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
 // Login 用户登录
 func (ctrl *UserController) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
-	token, refreshToken, err := ctrl.userService.Login(req.Username, req.Password)
+	result, err := ctrl.userService.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.Success(c, gin.H{
-		"token":         token,
-		"refresh_token": refreshToken,
-	})
+	utils.Success(c, newLoginResponse(result))
 }
 
 // GetProfile 获取当前用户信息
 func (ctrl *UserController) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		utils.Unauthorized(c, "未授权")
+		utils.Unauthorized(c, "unauthorized")
 		return
 	}
 
-	user, err := ctrl.userService.GetUserByID(userID.(uint))
+	user, err := ctrl.userService.GetUserByID(c.Request.Context(), userID.(uint))
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
@@ -95,58 +107,139 @@ func (ctrl *UserController) GetProfile(c *gin.Context) {
 // UpdateProfileRequest 更新用户信息请求
 type UpdateProfileRequest struct {
 	Nickname string `json:"nickname" binding:"max=50"`
-	Email    string `json:"email" binding:"omitempty,email"`
+	Email    string `json:"email" binding:"omitempty,email,max=100"` // 新邮箱验证后才会生效
 	Avatar   string `json:"avatar" binding:"max=255"`
+	Locale   string `json:"locale" binding:"max=10"` // zh-CN、en，"-" 表示清除
 }
 
 // UpdateProfile 更新用户信息
 func (ctrl *UserController) UpdateProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		utils.Unauthorized(c, "未授权")
+		utils.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
 		return
 	}
 
-	if err := ctrl.userService.UpdateUser(userID.(uint), req.Nickname, req.Email, req.Avatar); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.userService.UpdateUser(c.Request.Context(), userID.(uint), req.Nickname, req.Email, req.Avatar, req.Locale); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "更新成功", nil)
+	utils.SuccessWithMsg(c, "update_success", nil)
 }
 
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6,max=50"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=50,password"`
 }
 
 // ChangePassword 修改密码
 func (ctrl *UserController) ChangePassword(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		utils.Unauthorized(c, "未授权")
+		utils.Unauthorized(c, "unauthorized")
 		return
 	}
 
 	var req ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.BadRequest(c, "参数错误: "+err.Error())
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if err := ctrl.userService.ChangePassword(c.Request.Context(), userID.(uint), req.OldPassword, req.NewPassword); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "password_changed", nil)
+}
+
+// VerifyEmailQuery 邮箱验证参数
+type VerifyEmailQuery struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// VerifyEmail 通过验证链接验证邮箱
+func (ctrl *UserController) VerifyEmail(c *gin.Context) {
+	var query VerifyEmailQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if err := ctrl.userService.VerifyEmail(c.Request.Context(), query.Token); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "email_verified", nil)
+}
+
+// ResendVerification 重新发送验证邮件
+func (ctrl *UserController) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := ctrl.userService.ResendVerification(c.Request.Context(), userID.(uint)); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "verification_sent", nil)
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=100"`
+}
+
+// ForgotPassword 发送重置密码邮件，邮箱是否注册都返回相同的响应
+func (ctrl *UserController) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
+		return
+	}
+
+	if err := ctrl.userService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		utils.AbortWithError(c, err)
+		return
+	}
+
+	utils.SuccessWithMsg(c, "password_reset_requested", nil)
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=50,password"`
+}
+
+// ResetPassword 使用重置链接中的令牌设置新密码
+func (ctrl *UserController) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.InvalidParams(c, err)
 		return
 	}
 
-	if err := ctrl.userService.ChangePassword(userID.(uint), req.OldPassword, req.NewPassword); err != nil {
-		utils.Error(c, err.Error())
+	if err := ctrl.userService.ResetPasswordByToken(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		utils.AbortWithError(c, err)
 		return
 	}
 
-	utils.SuccessWithMsg(c, "密码修改成功", nil)
+	utils.SuccessWithMsg(c, "password_reset_success", nil)
 }
 
 // GetUserByID 根据ID获取用户信息
@@ -154,13 +247,13 @@ func (ctrl *UserController) GetUserByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		utils.BadRequest(c, "无效的用户ID")
+		utils.BadRequest(c, "invalid_user_id")
 		return
 	}
 
-	user, err := ctrl.userService.GetUserByID(uint(id))
+	user, err := ctrl.userService.GetUserByID(c.Request.Context(), uint(id))
 	if err != nil {
-		utils.Error(c, err.Error())
+		utils.AbortWithError(c, err)
 		return
 	}
 
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
 )
 
 // CORS 跨域中间件
@@ -12,12 +14,14 @@ func CORS() gin.HandlerFunc {
 		method := c.Request.Method
 		origin := c.Request.Header.Get("Origin")
 
-		if origin != "" {
+		// 每次请求读取配置，支持热更新允许的来源
+		if origin != "" && isAllowedOrigin(origin, config.Get().CORS.AllowedOrigins) {
 			c.Header("Access-Control-Allow-Origin", origin)
 			c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization")
-			c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
+			c.Header("Access-Control-Allow-Headers", "Origin, X-Requested-With, Content-Type, Accept, Authorization, X-Request-ID, traceparent, tracestate")
+			c.Header("Access-Control-Expose-Headers", "Content-Length, X-Request-ID, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Cache-Control, Content-Language, Content-Type")
 			c.Header("Access-Control-Allow-Credentials", "true")
+			c.Header("Vary", "Origin")
 		}
 
 		if method == "OPTIONS" {
@@ -28,3 +32,16 @@ func CORS() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// isAllowedOrigin 检查来源是否允许，未配置或包含 "*" 时允许所有来源
+func isAllowedOrigin(origin string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, o := range allowed {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+)
+
+// ErrorHandler 统一错误处理中间件
+// 处理器通过 utils.AbortWithError 上报错误，由这里转换为对应的 HTTP 状态码和错误码；
+// 内部错误记录详细日志，响应中只返回通用提示
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		e := apperr.From(err)
+		if e.Status >= http.StatusInternalServerError {
+			logger.WithContext(c.Request.Context()).Error("请求处理失败",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err),
+			)
+		}
+
+		utils.Fail(c, e)
+	}
+}
+
+// Recovery 捕获 panic，记录日志并返回统一的内部错误响应
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		logger.WithContext(c.Request.Context()).Error("请求处理发生panic",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Any("panic", recovered),
+		)
+
+		utils.Fail(c, apperr.ErrInternal)
+		c.Abort()
+	})
+}
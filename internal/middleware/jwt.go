@@ -1,24 +1,37 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
 	"github.com/xiaoxin/blog-backend/pkg/jwt"
 )
 
+// 认证令牌错误
+var (
+	errTokenMissing   = apperr.Unauthorized("token_missing", "缺少认证令牌")
+	errTokenMalformed = apperr.Unauthorized("token_malformed", "认证令牌格式错误")
+	errTokenInvalid   = apperr.Unauthorized("token_invalid", "无效的认证令牌")
+)
+
+// SessionValidator 校验令牌对应的用户当前是否仍可访问
+type SessionValidator interface {
+	// ValidateSession 校验用户状态和令牌版本，返回用户当前的角色
+	ValidateSession(ctx context.Context, userID uint, tokenVersion int) (string, error)
+}
+
 // JWTAuth JWT认证中间件
-func JWTAuth() gin.HandlerFunc {
+// validator 不为 nil 时校验用户是否被封禁或强制下线，并以用户当前的角色代替令牌中的角色
+func JWTAuth(validator SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "缺少认证令牌",
-			})
+			utils.Fail(c, errTokenMissing)
 			c.Abort()
 			return
 		}
@@ -26,10 +39,7 @@ func JWTAuth() gin.HandlerFunc {
 		// 检查Bearer前缀
 		parts := strings.SplitN(authHeader, " ", 2)
 		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "认证令牌格式错误",
-			})
+			utils.Fail(c, errTokenMalformed)
 			c.Abort()
 			return
 		}
@@ -37,18 +47,43 @@ func JWTAuth() gin.HandlerFunc {
 		// 解析令牌
 		claims, err := jwt.ParseToken(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "无效的认证令牌",
-			})
+			utils.Fail(c, errTokenInvalid)
 			c.Abort()
 			return
 		}
 
+		role := claims.Role
+		if validator != nil {
+			if role, err = validator.ValidateSession(c.Request.Context(), claims.UserID, claims.Version); err != nil {
+				utils.Fail(c, err)
+				c.Abort()
+				return
+			}
+		}
+
 		// 将用户信息存储到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("role", role)
+		c.Next()
+	}
+}
+
+// OptionalAuth 可选认证中间件，用于公开接口
+// 携带有效令牌时与 JWTAuth 一样写入用户信息，令牌缺失、无效或用户已不可访问时按匿名访问处理
+func OptionalAuth(validator SessionValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := bearerClaims(c); ok {
+			role, err := claims.Role, error(nil)
+			if validator != nil {
+				role, err = validator.ValidateSession(c.Request.Context(), claims.UserID, claims.Version)
+			}
+			if err == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("role", role)
+			}
+		}
 		c.Next()
 	}
 }
@@ -58,10 +93,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code": 401,
-				"msg":  "未授权访问",
-			})
+			utils.Unauthorized(c, "unauthorized_access")
 			c.Abort()
 			return
 		}
@@ -76,10 +108,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		}
 
 		if !hasPermission {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code": 403,
-				"msg":  "权限不足",
-			})
+			utils.Fail(c, apperr.ErrForbidden)
 			c.Abort()
 			return
 		}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/jwt"
+)
+
+func TestAuthErrorResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwt.InitJWT("test-secret")
+	prev := config.Get()
+	t.Cleanup(func() { config.Set(prev) })
+
+	tests := []struct {
+		name       string
+		handlers   []gin.HandlerFunc
+		header     string
+		wantStatus int
+		wantCode   string
+	}{
+		{"token missing", []gin.HandlerFunc{JWTAuth(nil)}, "", http.StatusUnauthorized, "token_missing"},
+		{"token malformed", []gin.HandlerFunc{JWTAuth(nil)}, "Token abc", http.StatusUnauthorized, "token_malformed"},
+		{"token invalid", []gin.HandlerFunc{JWTAuth(nil)}, "Bearer abc", http.StatusUnauthorized, "token_invalid"},
+		{"no role", []gin.HandlerFunc{RequireRole("admin")}, "", http.StatusUnauthorized, "unauthorized"},
+		{"wrong role", []gin.HandlerFunc{func(c *gin.Context) { c.Set("role", "user") }, RequireRole("admin")}, "", http.StatusForbidden, "forbidden"},
+	}
+	for _, legacy := range []bool{false, true} {
+		config.Set(&config.Config{App: config.AppConfig{LegacyStatusCode: legacy}})
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				r := gin.New()
+				r.GET("/", append(tt.handlers, func(c *gin.Context) { c.Status(http.StatusNoContent) })...)
+
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				if tt.header != "" {
+					req.Header.Set("Authorization", tt.header)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				// 开启 legacy_status_code 时 HTTP 状态码统一为 200，真实状态码在响应体中
+				wantStatus := tt.wantStatus
+				if legacy {
+					wantStatus = http.StatusOK
+				}
+				if w.Code != wantStatus {
+					t.Errorf("legacy = %v: status = %d, want %d", legacy, w.Code, wantStatus)
+				}
+				var resp utils.Response
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatal(err)
+				}
+				if resp.Code != tt.wantStatus || resp.Error != tt.wantCode || resp.Msg == "" {
+					t.Errorf("legacy = %v: response = %+v, want code %d error %q", legacy, resp, tt.wantStatus, tt.wantCode)
+				}
+			})
+		}
+	}
+}
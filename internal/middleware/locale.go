@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/pkg/database"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+)
+
+// LocalePreference 用户语言偏好来源
+type LocalePreference interface {
+	// PreferredLocale 获取用户的语言偏好，未设置时返回空字符串
+	PreferredLocale(ctx context.Context, userID uint) string
+}
+
+// Locale 语言协商中间件
+// 按 ?lang= 参数、登录用户的语言偏好、Accept-Language 请求头的顺序选择语言，都没有时使用默认语言
+func Locale(pref LocalePreference) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := resolveLocale(c, pref)
+
+		c.Set("locale", locale)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
+
+// resolveLocale 选择当前请求的语言
+func resolveLocale(c *gin.Context, pref LocalePreference) string {
+	if lang := c.Query("lang"); lang != "" {
+		if locale, ok := i18n.Normalize(lang); ok {
+			return locale
+		}
+	}
+
+	if pref != nil {
+		if userID, ok := bearerUserID(c); ok {
+			// 读主库，避免副本延迟导致刚修改的偏好被缓存为旧值
+			if locale := pref.PreferredLocale(database.WithPrimary(c.Request.Context()), userID); locale != "" {
+				return locale
+			}
+		}
+	}
+
+	return i18n.Match(c.GetHeader("Accept-Language"))
+}
@@ -19,7 +19,7 @@ func Logger() gin.HandlerFunc {
 		c.Next()
 
 		cost := time.Since(start)
-		logger.Info("HTTP Request",
+		logger.WithContext(c.Request.Context()).Info("HTTP Request",
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// ipLimiter 单个客户端的限流器
+type ipLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// rateLimiterStore 按IP保存限流器，配置变化时整体重建
+type rateLimiterStore struct {
+	mu        sync.Mutex
+	settings  config.RateLimitConfig
+	limiters  map[string]*ipLimiter
+	lastSweep time.Time
+}
+
+// limiterIdleTTL 限流器闲置超过该时间后被清理
+const limiterIdleTTL = 10 * time.Minute
+
+func (s *rateLimiterStore) get(ip string, settings config.RateLimitConfig) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.limiters == nil || s.settings != settings {
+		s.settings = settings
+		s.limiters = make(map[string]*ipLimiter)
+		s.lastSweep = now
+	}
+
+	// 定期清理闲置的限流器
+	if now.Sub(s.lastSweep) > limiterIdleTTL {
+		for k, l := range s.limiters {
+			if now.Sub(l.lastSeen) > limiterIdleTTL {
+				delete(s.limiters, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	l, ok := s.limiters[ip]
+	if !ok {
+		l = &ipLimiter{limiter: rate.NewLimiter(rate.Limit(settings.RequestsPerSecond), settings.Burst)}
+		s.limiters[ip] = l
+	}
+	l.lastSeen = now
+	return l.limiter
+}
+
+// RateLimit 按客户端IP限流中间件，限流参数支持热更新
+func RateLimit() gin.HandlerFunc {
+	store := &rateLimiterStore{}
+
+	return func(c *gin.Context) {
+		settings := config.Get().RateLimit
+		if !settings.Enabled {
+			c.Next()
+			return
+		}
+
+		if !store.get(c.ClientIP(), settings).Allow() {
+			utils.Fail(c, apperr.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prev := config.Get()
+	t.Cleanup(func() { config.Set(prev) })
+	config.Set(&config.Config{RateLimit: config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 2}})
+
+	r := gin.New()
+	r.GET("/", RateLimit(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	wantStatus := []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests}
+	for i, want := range wantStatus {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != want {
+			t.Fatalf("request %d status = %d, want %d", i, w.Code, want)
+		}
+		if want != http.StatusTooManyRequests {
+			continue
+		}
+		var resp utils.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Code != http.StatusTooManyRequests || resp.Error != "too_many_requests" {
+			t.Errorf("response = %+v", resp)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/database"
+	"github.com/xiaoxin/blog-backend/pkg/jwt"
+)
+
+// stickyStore 记录用户最近一次写入后需要读主库的截止时间
+type stickyStore struct {
+	mu        sync.Mutex
+	until     map[uint]time.Time
+	lastSweep time.Time
+}
+
+// active 判断用户当前是否需要读主库
+func (s *stickyStore) active(userID uint, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return now.Before(s.until[userID])
+}
+
+// mark 标记用户在 until 之前读主库
+func (s *stickyStore) mark(userID uint, now, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.until == nil {
+		s.until = make(map[uint]time.Time)
+		s.lastSweep = now
+	}
+
+	// 定期清理已过期的记录
+	if now.Sub(s.lastSweep) > time.Minute {
+		for id, t := range s.until {
+			if now.After(t) {
+				delete(s.until, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.until[userID] = until
+}
+
+// ReadYourWrites 写后读一致性中间件
+// 用户写入成功后的 database.sticky_window 秒内，该用户的请求全部读主库，避免读到副本上的旧数据
+// 标记保存在进程内，多实例部署时需配合会话保持使用
+func ReadYourWrites() gin.HandlerFunc {
+	store := &stickyStore{}
+
+	return func(c *gin.Context) {
+		dbCfg := config.Get().Database
+		window := dbCfg.GetStickyWindow()
+		if len(dbCfg.Replicas) == 0 || window <= 0 {
+			c.Next()
+			return
+		}
+
+		userID, ok := bearerUserID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if store.active(userID, time.Now()) {
+			c.Request = c.Request.WithContext(database.WithPrimary(c.Request.Context()))
+		}
+
+		c.Next()
+
+		if isWriteMethod(c.Request.Method) && c.Writer.Status() < http.StatusBadRequest {
+			now := time.Now()
+			store.mark(userID, now, now.Add(window))
+		}
+	}
+}
+
+// bearerUserID 从 Authorization 头中解析用户ID，令牌缺失或无效时返回 false
+func bearerUserID(c *gin.Context) (uint, bool) {
+	claims, ok := bearerClaims(c)
+	if !ok {
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// bearerClaims 从 Authorization 头中解析令牌声明，令牌缺失或无效时返回 false
+func bearerClaims(c *gin.Context) (*jwt.Claims, bool) {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, false
+	}
+
+	claims, err := jwt.ParseToken(parts[1])
+	if err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// isWriteMethod 判断是否为写请求
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
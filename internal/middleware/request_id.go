@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/pkg/requestid"
+)
+
+// RequestID 请求ID中间件，透传或生成 X-Request-ID
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.HeaderName)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.HeaderName, id)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// TwoFactorChecker 检查用户是否已启用两步验证
+type TwoFactorChecker interface {
+	// CheckTwoFactorEnabled 未启用两步验证时返回错误
+	CheckTwoFactorEnabled(ctx context.Context, userID uint) error
+}
+
+// RequireAdminTwoFactor 管理员两步验证中间件，需放在 JWTAuth 和 RequireRole 之后
+// two_factor.require_for_admin 开启时，未启用两步验证的管理员不能访问管理接口，策略支持热更新
+func RequireAdminTwoFactor(checker TwoFactorChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Get()
+		if cfg == nil || !cfg.TwoFactor.RequireForAdmin {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("user_id")
+		if !exists {
+			utils.Unauthorized(c, "unauthorized_access")
+			c.Abort()
+			return
+		}
+
+		if err := checker.CheckTwoFactorEnabled(c.Request.Context(), userID.(uint)); err != nil {
+			utils.Fail(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/utils"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// EmailVerifier 检查用户邮箱是否已验证
+type EmailVerifier interface {
+	// CheckEmailVerified 邮箱未验证时返回错误
+	CheckEmailVerified(ctx context.Context, userID uint) error
+}
+
+// RequireVerifiedEmail 邮箱验证中间件，需放在 JWTAuth 之后
+// email_verification.restrict 包含 action 时，邮箱未验证的用户不能执行该操作，策略支持热更新
+func RequireVerifiedEmail(verifier EmailVerifier, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Get()
+		if cfg == nil || !cfg.EmailVerification.Restricts(action) {
+			c.Next()
+			return
+		}
+
+		userID, exists := c.Get("user_id")
+		if !exists {
+			utils.Unauthorized(c, "unauthorized_access")
+			c.Abort()
+			return
+		}
+
+		if err := verifier.CheckEmailVerified(c.Request.Context(), userID.(uint)); err != nil {
+			utils.Fail(c, err)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
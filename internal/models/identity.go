@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// UserIdentity 用户关联的第三方登录身份，同一身份提供方的同一用户只能关联一个本站账号
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_identities_user_provider" json:"-"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject;uniqueIndex:idx_user_identities_user_provider" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"` // 身份提供方的用户标识（sub）
+	Email     string    `gorm:"type:varchar(100);not null;default:''" json:"email"`                                   // 关联时身份提供方返回的邮箱，仅用于展示
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
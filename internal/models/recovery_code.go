@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RecoveryCode 两步验证的恢复码，只保存哈希，每个恢复码只能使用一次
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"-"`
+	CreatedAt time.Time  `json:"-"`
+	UserID    uint       `gorm:"not null;index" json:"-"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"-"` // 使用时间，为空表示未使用
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -1,17 +1,58 @@
 package models
 
+import "time"
+
 // User 用户模型
 type User struct {
 	BaseModel
-	Username string `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
-	Password string `gorm:"type:varchar(255);not null" json:"-"`
-	Email    string `gorm:"type:varchar(100);uniqueIndex" json:"email"`
-	Nickname string `gorm:"type:varchar(50)" json:"nickname"`
-	Avatar   string `gorm:"type:varchar(255)" json:"avatar"`
-	Role     string `gorm:"type:varchar(20);default:'user'" json:"role"` // admin, user
-	Status   int    `gorm:"default:1" json:"status"`                     // 1:正常 0:禁用
-	Articles []Article `gorm:"foreignKey:AuthorID" json:"articles,omitempty"`
-	Comments []Comment `gorm:"foreignKey:UserID" json:"comments,omitempty"`
+	Username        string     `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
+	Password        string     `gorm:"type:varchar(255);not null" json:"-"`
+	Email           string     `gorm:"type:varchar(100);uniqueIndex;default:null" json:"email"`              // 未填写时存为 NULL，避免与唯一索引冲突
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`                                                    // 邮箱验证时间，为空表示未验证
+	PendingEmail    string     `gorm:"type:varchar(100);not null;default:''" json:"pending_email,omitempty"` // 待验证的新邮箱，验证后替换 Email
+	Nickname        string     `gorm:"type:varchar(50)" json:"nickname"`
+	Avatar          string     `gorm:"type:varchar(255)" json:"avatar"`
+	Role            string     `gorm:"type:varchar(20);default:'user'" json:"role"`        // admin, user
+	Status          int        `gorm:"default:1" json:"status"`                            // 1:正常 0:禁用
+	Locale          string     `gorm:"type:varchar(10);not null;default:''" json:"locale"` // 语言偏好，为空时按 Accept-Language 选择
+	BanReason       string     `gorm:"type:varchar(255);not null;default:''" json:"ban_reason,omitempty"`
+	BannedUntil     *time.Time `json:"banned_until,omitempty"`                                           // 封禁到期时间，为空且状态为禁用时表示永久封禁
+	TokenVersion    int        `gorm:"not null;default:0" json:"-"`                                      // 令牌版本，递增后已签发的令牌失效
+	TOTPSecret      string     `gorm:"column:totp_secret;type:varchar(64);not null;default:''" json:"-"` // 两步验证密钥，启用前为待确认的密钥
+	TOTPEnabledAt   *time.Time `gorm:"column:totp_enabled_at" json:"totp_enabled_at,omitempty"`          // 两步验证启用时间，为空表示未启用
+	TOTPLastStep    int64      `gorm:"column:totp_last_step;not null;default:0" json:"-"`                // 最近一次通过验证的时间步，防止动态码重放
+	Articles        []Article  `gorm:"foreignKey:AuthorID" json:"articles,omitempty"`
+	Comments        []Comment  `gorm:"foreignKey:UserID" json:"comments,omitempty"`
+}
+
+// 用户角色
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// 用户状态
+const (
+	UserStatusDisabled = 0
+	UserStatusActive   = 1
+)
+
+// IsBanned 判断用户在指定时间是否处于封禁状态，临时封禁到期后自动解除
+func (u *User) IsBanned(now time.Time) bool {
+	if u.Status == UserStatusActive {
+		return false
+	}
+	return u.BannedUntil == nil || now.Before(*u.BannedUntil)
+}
+
+// EmailVerified 判断当前邮箱是否已验证
+func (u *User) EmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled 判断是否已启用两步验证
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
 }
 
 // TableName 指定表名
@@ -0,0 +1,250 @@
+package gormrepo
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// articleRepository 文章仓储
+type articleRepository struct {
+	db *gorm.DB
+}
+
+// NewArticleRepository 创建文章仓储
+func NewArticleRepository(db *gorm.DB) repository.ArticleRepository {
+	return &articleRepository{db: db}
+}
+
+// 预加载关联时只查询的列，作者只包含公开信息
+var (
+	authorColumns   = []string{"id", "username", "nickname", "avatar"}
+	categoryColumns = []string{"id", "name"}
+	tagColumns      = []string{"id", "name"}
+)
+
+// preloadAll 预加载作者、分类和标签
+func preloadAll(db *gorm.DB) *gorm.DB {
+	return db.Preload("Author", selectColumns(authorColumns)).
+		Preload("Category", selectColumns(categoryColumns)).
+		Preload("Tags", selectColumns(tagColumns))
+}
+
+// selectColumns 限制预加载查询的列
+func selectColumns(columns []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Select(columns)
+	}
+}
+
+func (r *articleRepository) Create(ctx context.Context, article *models.Article) error {
+	return r.db.WithContext(ctx).Create(article).Error
+}
+
+func (r *articleRepository) FindByID(ctx context.Context, id uint) (*models.Article, error) {
+	var article models.Article
+	if err := preloadAll(r.db.WithContext(ctx)).First(&article, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &article, nil
+}
+
+func (r *articleRepository) List(ctx context.Context, filter repository.ArticleFilter, page repository.ArticlePage) ([]models.Article, error) {
+	var articles []models.Article
+
+	query := r.filtered(ctx, filter)
+	if sel := page.Select; sel != nil {
+		if len(sel.Columns) > 0 {
+			query = query.Select(qualify(sel.Columns))
+		}
+		if sel.Author {
+			query = query.Preload("Author", selectColumns(authorColumns))
+		}
+		if sel.Category {
+			query = query.Preload("Category", selectColumns(categoryColumns))
+		}
+		if sel.Tags {
+			query = query.Preload("Tags", selectColumns(tagColumns))
+		}
+	} else {
+		query = preloadAll(query)
+	}
+
+	// 游标分页：按排序键比较，向前翻页时反向查询后再倒转结果
+	columns := page.Sort.Columns()
+	switch {
+	case page.After != nil:
+		cond, args := keysetCondition(columns, *page.After, false)
+		query = query.Where(cond, args...).Order(orderClause(columns, false))
+	case page.Before != nil:
+		cond, args := keysetCondition(columns, *page.Before, true)
+		query = query.Where(cond, args...).Order(orderClause(columns, true))
+	default:
+		query = query.Order(orderClause(columns, false)).Offset(page.Offset)
+	}
+	if page.Limit > 0 {
+		query = query.Limit(page.Limit)
+	}
+
+	if err := query.Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	if page.Before != nil {
+		slices.Reverse(articles)
+	}
+	return articles, nil
+}
+
+func (r *articleRepository) Count(ctx context.Context, filter repository.ArticleFilter) (int64, error) {
+	var total int64
+	err := r.filtered(ctx, filter).Count(&total).Error
+	return total, err
+}
+
+// filtered 应用筛选条件
+func (r *articleRepository) filtered(ctx context.Context, filter repository.ArticleFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Article{})
+	if filter.Status != nil {
+		query = query.Where("articles.status = ?", *filter.Status)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("articles.category_id = ?", *filter.CategoryID)
+	}
+	if filter.AuthorID != nil {
+		query = query.Where("articles.author_id = ?", *filter.AuthorID)
+	}
+	if filter.Keyword != "" {
+		query = query.Where("LOWER(articles.title) LIKE ? ESCAPE '!'", "%"+escapeLike(strings.ToLower(filter.Keyword))+"%")
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("articles.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("articles.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		query = query.Where("articles.updated_at >= ?", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		query = query.Where("articles.updated_at < ?", *filter.UpdatedTo)
+	}
+
+	if len(filter.TagIDs) > 0 {
+		tagged := r.db.Table("article_tags").Select("article_id").Where("tag_id IN ?", filter.TagIDs)
+		if filter.MatchAllTags {
+			tagged = tagged.Group("article_id").Having("COUNT(DISTINCT tag_id) = ?", len(distinct(filter.TagIDs)))
+		}
+		query = query.Where("articles.id IN (?)", tagged)
+	}
+	return query
+}
+
+// qualify 为列名加上表名前缀
+func qualify(columns []string) []string {
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = "articles." + column
+	}
+	return qualified
+}
+
+// orderClause 生成排序子句，reverse 为 true 时反转每列的方向
+func orderClause(columns []repository.SortColumn, reverse bool) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		dir := " ASC"
+		if column.Desc != reverse {
+			dir = " DESC"
+		}
+		parts[i] = "articles." + column.Name + dir
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetCondition 排序键与游标比较的条件，选出排在游标之后（reverse 为 true 时之前）的记录
+// 前面的列可能相同，依次展开为 (a > ?) OR (a = ? AND b > ?) ...
+func keysetCondition(columns []repository.SortColumn, key repository.ArticleKey, reverse bool) (string, []interface{}) {
+	var (
+		clauses []string
+		args    []interface{}
+	)
+	for i, column := range columns {
+		var parts []string
+		for _, prev := range columns[:i] {
+			parts = append(parts, "articles."+prev.Name+" = ?")
+			args = append(args, key.Value(prev.Name))
+		}
+
+		op := " > ?"
+		if column.Desc != reverse {
+			op = " < ?"
+		}
+		parts = append(parts, "articles."+column.Name+op)
+		args = append(args, key.Value(column.Name))
+
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// escapeLike 转义 LIKE 中的通配符，转义字符为 !
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// distinct 去掉重复的ID
+func distinct(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+func (r *articleRepository) Update(ctx context.Context, id uint, article *models.Article) error {
+	db := r.db.WithContext(ctx)
+
+	// 标签由 ReplaceTags 单独维护，避免 Updates 时写入关联
+	result := db.Model(&models.Article{}).Where("id = ?", id).Omit("Tags", "Author", "Category", "Comments").Updates(article)
+	return checkAffected(db, result, &models.Article{}, id)
+}
+
+func (r *articleRepository) ReplaceTags(ctx context.Context, id uint, tags []models.Tag) error {
+	article := models.Article{BaseModel: models.BaseModel{ID: id}}
+	return r.db.WithContext(ctx).Model(&article).Association("Tags").Replace(tags)
+}
+
+func (r *articleRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Article{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *articleRepository) IncrementViewCount(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.Article{}).Where("id = ?", id).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).Error
+}
+
+func (r *articleRepository) IncrementLikeCount(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.Article{}).Where("id = ?", id).UpdateColumn("like_count", gorm.Expr("like_count + ?", 1)).Error
+}
+
+func (r *articleRepository) CountByCategory(ctx context.Context, categoryID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Article{}).Where("category_id = ?", categoryID).Count(&count).Error
+	return count, err
+}
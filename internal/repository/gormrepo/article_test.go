@@ -0,0 +1,105 @@
+package gormrepo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+func TestOrderClause(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    repository.ArticleSort
+		reverse bool
+		want    string
+	}{
+		{"default", repository.ArticleSort{}, false, "articles.is_top DESC, articles.created_at DESC, articles.id DESC"},
+		{"default reversed", repository.ArticleSort{}, true, "articles.is_top ASC, articles.created_at ASC, articles.id ASC"},
+		{"ascending", repository.ArticleSort{Field: repository.ArticleSortViews}, false, "articles.view_count ASC, articles.id ASC"},
+		{"ascending reversed", repository.ArticleSort{Field: repository.ArticleSortViews}, true, "articles.view_count DESC, articles.id DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderClause(tt.sort.Columns(), tt.reverse); got != tt.want {
+				t.Errorf("orderClause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := repository.ArticleKey{IsTop: true, CreatedAt: created, ViewCount: 42, ID: 7}
+
+	tests := []struct {
+		name     string
+		sort     repository.ArticleSort
+		reverse  bool
+		wantCond string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "default after",
+			sort:     repository.ArticleSort{},
+			wantCond: "((articles.is_top < ?) OR (articles.is_top = ? AND articles.created_at < ?) OR (articles.is_top = ? AND articles.created_at = ? AND articles.id < ?))",
+			wantArgs: []interface{}{true, true, created, true, created, uint(7)},
+		},
+		{
+			name:     "default before",
+			sort:     repository.ArticleSort{},
+			reverse:  true,
+			wantCond: "((articles.is_top > ?) OR (articles.is_top = ? AND articles.created_at > ?) OR (articles.is_top = ? AND articles.created_at = ? AND articles.id > ?))",
+			wantArgs: []interface{}{true, true, created, true, created, uint(7)},
+		},
+		{
+			name:     "ascending after",
+			sort:     repository.ArticleSort{Field: repository.ArticleSortViews},
+			wantCond: "((articles.view_count > ?) OR (articles.view_count = ? AND articles.id > ?))",
+			wantArgs: []interface{}{42, 42, uint(7)},
+		},
+		{
+			name:     "ascending before",
+			sort:     repository.ArticleSort{Field: repository.ArticleSortViews},
+			reverse:  true,
+			wantCond: "((articles.view_count < ?) OR (articles.view_count = ? AND articles.id < ?))",
+			wantArgs: []interface{}{42, 42, uint(7)},
+		},
+		{
+			name:     "descending after",
+			sort:     repository.ArticleSort{Field: repository.ArticleSortViews, Desc: true},
+			wantCond: "((articles.view_count < ?) OR (articles.view_count = ? AND articles.id < ?))",
+			wantArgs: []interface{}{42, 42, uint(7)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args := keysetCondition(tt.sort.Columns(), key, tt.reverse)
+			if cond != tt.wantCond {
+				t.Errorf("keysetCondition() cond = %q, want %q", cond, tt.wantCond)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("keysetCondition() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"golang", "golang"},
+		{"100%", "100!%"},
+		{"snake_case", "snake!_case"},
+		{"wow!", "wow!!"},
+		{"!%_", "!!!%!_"},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.in); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
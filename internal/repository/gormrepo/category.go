@@ -0,0 +1,70 @@
+package gormrepo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// categoryRepository 分类仓储
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository 创建分类仓储
+func NewCategoryRepository(db *gorm.DB) repository.CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) List(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	if err := r.db.WithContext(ctx).Order("sort ASC, id DESC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) ExistsByName(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Category{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	db := r.db.WithContext(ctx)
+
+	updates := map[string]interface{}{
+		"name":        category.Name,
+		"description": category.Description,
+		"sort":        category.Sort,
+	}
+
+	result := db.Model(&models.Category{}).Where("id = ?", category.ID).Updates(updates)
+	return checkAffected(db, result, &models.Category{}, category.ID)
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Category{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
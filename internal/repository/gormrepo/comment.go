@@ -0,0 +1,40 @@
+package gormrepo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// commentRepository 评论仓储
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository 创建评论仓储
+func NewCommentRepository(db *gorm.DB) repository.CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID).
+		Preload("Article", selectColumns([]string{"id", "title"})).
+		Order("id DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Comment{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
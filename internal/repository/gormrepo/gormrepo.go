@@ -0,0 +1,56 @@
+// Package gormrepo 基于 GORM 的仓储实现
+package gormrepo
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// NewRepositories 创建基于 GORM 的仓储集合
+func NewRepositories(db *gorm.DB) *repository.Repositories {
+	return &repository.Repositories{
+		Users:      NewUserRepository(db),
+		Articles:   NewArticleRepository(db),
+		Categories: NewCategoryRepository(db),
+		Tags:       NewTagRepository(db),
+		Comments:   NewCommentRepository(db),
+		UnitOfWork: NewUnitOfWork(db),
+
+		RecoveryCodes: NewRecoveryCodeRepository(db),
+		Identities:    NewIdentityRepository(db),
+	}
+}
+
+// translateError 将 GORM 的记录不存在和唯一约束错误转换为 repository.ErrNotFound 和 repository.ErrDuplicate
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repository.ErrNotFound
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repository.ErrDuplicate
+	}
+	return err
+}
+
+// checkAffected 更新未影响任何行时确认记录是否存在
+// MySQL 在值未变化时 RowsAffected 为 0，不能直接视为记录不存在
+func checkAffected(db *gorm.DB, result *gorm.DB, model interface{}, id uint) error {
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
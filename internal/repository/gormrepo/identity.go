@@ -0,0 +1,50 @@
+package gormrepo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// identityRepository 第三方登录身份仓储
+type identityRepository struct {
+	db *gorm.DB
+}
+
+// NewIdentityRepository 创建第三方登录身份仓储
+func NewIdentityRepository(db *gorm.DB) repository.IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return translateError(r.db.WithContext(ctx).Create(identity).Error)
+}
+
+func (r *identityRepository) FindBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) ListByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+func (r *identityRepository) Delete(ctx context.Context, userID uint, provider string) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
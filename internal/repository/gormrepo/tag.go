@@ -0,0 +1,32 @@
+package gormrepo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// tagRepository 标签仓储
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository 创建标签仓储
+func NewTagRepository(db *gorm.DB) repository.TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Tag, error) {
+	var tags []models.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,68 @@
+package gormrepo
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/database"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+)
+
+// unitOfWork 基于数据库事务的工作单元
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// txKey 当前事务在 context 中的键
+type txKey struct{}
+
+// NewUnitOfWork 创建工作单元
+func NewUnitOfWork(db *gorm.DB) repository.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+// Do 在事务中执行 fn，遇到死锁、锁等待超时等瞬时错误时整体重试
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos *repository.Repositories) error) error {
+	// 已在事务中（包括在事务回调里调用了外层的工作单元），直接加入外层事务
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok && repository.InTx(ctx) {
+		return fn(ctx, newTxRepositories(tx))
+	}
+
+	for attempt := 1; ; attempt++ {
+		txCtx, hooks := repository.WithCommitHooks(ctx)
+		err := u.db.WithContext(txCtx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(txCtx, txKey{}, tx), newTxRepositories(tx))
+		})
+		if err == nil {
+			hooks.Run()
+			return nil
+		}
+
+		if !database.IsRetryable(err) || attempt >= database.MaxTxAttempts {
+			return err
+		}
+
+		logger.WithContext(ctx).Warn("事务冲突，准备重试",
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+
+		// 线性退避，避免冲突的事务立即再次相撞
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 20 * time.Millisecond):
+		}
+	}
+}
+
+// newTxRepositories 创建绑定到事务的仓储集合
+func newTxRepositories(tx *gorm.DB) *repository.Repositories {
+	repos := NewRepositories(tx)
+	repos.UnitOfWork = &unitOfWork{db: tx}
+	return repos
+}
@@ -0,0 +1,181 @@
+package gormrepo
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// userRepository 用户仓储
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建用户仓储
+func NewUserRepository(db *gorm.DB) repository.UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return translateError(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepository) Update(ctx context.Context, id uint, update repository.UserUpdate) error {
+	db := r.db.WithContext(ctx)
+
+	updates := make(map[string]interface{})
+	if update.Nickname != nil {
+		updates["nickname"] = *update.Nickname
+	}
+	if update.Email != nil {
+		updates["email"] = *update.Email
+	}
+	if update.Avatar != nil {
+		updates["avatar"] = *update.Avatar
+	}
+	if update.Password != nil {
+		updates["password"] = *update.Password
+	}
+	if update.Role != nil {
+		updates["role"] = *update.Role
+	}
+	if update.Status != nil {
+		updates["status"] = *update.Status
+	}
+	if update.Locale != nil {
+		updates["locale"] = *update.Locale
+	}
+	if update.BanReason != nil {
+		updates["ban_reason"] = *update.BanReason
+	}
+	if update.BannedUntil != nil {
+		if update.BannedUntil.IsZero() {
+			updates["banned_until"] = nil
+		} else {
+			updates["banned_until"] = *update.BannedUntil
+		}
+	}
+
+	if update.PendingEmail != nil {
+		updates["pending_email"] = *update.PendingEmail
+	}
+	if update.EmailVerifiedAt != nil {
+		if update.EmailVerifiedAt.IsZero() {
+			updates["email_verified_at"] = nil
+		} else {
+			updates["email_verified_at"] = *update.EmailVerifiedAt
+		}
+	}
+
+	if update.TOTPSecret != nil {
+		updates["totp_secret"] = *update.TOTPSecret
+	}
+	if update.TOTPEnabledAt != nil {
+		if update.TOTPEnabledAt.IsZero() {
+			updates["totp_enabled_at"] = nil
+		} else {
+			updates["totp_enabled_at"] = *update.TOTPEnabledAt
+		}
+	}
+
+	if len(updates) == 0 {
+		_, err := r.FindByID(ctx, id)
+		return err
+	}
+
+	result := db.Model(&models.User{}).Where("id = ?", id).Updates(updates)
+	return checkAffected(db, result, &models.User{}, id)
+}
+
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	db := r.db.WithContext(ctx)
+	result := db.Model(&models.User{}).Where("id = ?", id).UpdateColumn("token_version", gorm.Expr("token_version + ?", 1))
+	return checkAffected(db, result, &models.User{}, id)
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	// 条件更新保证同一时间步的动态码并发提交时只有一个成功
+	result := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		UpdateColumn("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *userRepository) Search(ctx context.Context, filter repository.UserFilter, offset, limit int) ([]models.User, error) {
+	var users []models.User
+	query := r.filtered(ctx, filter).Order("id DESC").Offset(offset)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *userRepository) Count(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	var total int64
+	err := r.filtered(ctx, filter).Count(&total).Error
+	return total, err
+}
+
+// filtered 应用用户搜索条件
+func (r *userRepository) filtered(ctx context.Context, filter repository.UserFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.User{})
+	if filter.Username != "" {
+		query = query.Where("LOWER(username) LIKE ? ESCAPE '!'", "%"+escapeLike(strings.ToLower(filter.Username))+"%")
+	}
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) LIKE ? ESCAPE '!'", "%"+escapeLike(strings.ToLower(filter.Email))+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	return query
+}
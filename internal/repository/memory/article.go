@@ -0,0 +1,306 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// articleRepository 文章仓储
+type articleRepository struct {
+	store *Store
+}
+
+// NewArticleRepository 创建文章仓储
+func NewArticleRepository(store *Store) repository.ArticleRepository {
+	return &articleRepository{store: store}
+}
+
+func (r *articleRepository) Create(ctx context.Context, article *models.Article) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.stamp("articles", &article.BaseModel)
+	r.store.articles[article.ID] = stripArticle(*article)
+	return nil
+}
+
+func (r *articleRepository) FindByID(ctx context.Context, id uint) (*models.Article, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	article, ok := r.store.articles[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	article = r.hydrate(article)
+	return &article, nil
+}
+
+func (r *articleRepository) List(ctx context.Context, filter repository.ArticleFilter, page repository.ArticlePage) ([]models.Article, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.filtered(filter)
+	columns := page.Sort.Columns()
+	sort.Slice(matched, func(i, j int) bool {
+		return compareKeys(columns, repository.KeyOf(&matched[i]), repository.KeyOf(&matched[j])) < 0
+	})
+
+	start, end := page.Offset, len(matched)
+	switch {
+	case page.After != nil:
+		start = sort.Search(len(matched), func(i int) bool {
+			return compareKeys(columns, repository.KeyOf(&matched[i]), *page.After) > 0
+		})
+	case page.Before != nil:
+		start = 0
+		end = sort.Search(len(matched), func(i int) bool {
+			return compareKeys(columns, repository.KeyOf(&matched[i]), *page.Before) >= 0
+		})
+		if page.Limit > 0 && end-page.Limit > 0 {
+			start = end - page.Limit
+		}
+	}
+	if start > end {
+		start = end
+	}
+	if page.Limit > 0 && start+page.Limit < end {
+		end = start + page.Limit
+	}
+
+	articles := make([]models.Article, 0, end-start)
+	for _, article := range matched[start:end] {
+		articles = append(articles, r.hydrate(article))
+	}
+	return articles, nil
+}
+
+func (r *articleRepository) Count(ctx context.Context, filter repository.ArticleFilter) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.filtered(filter))), nil
+}
+
+// filtered 获取符合筛选条件的文章，调用方需持有读锁
+func (r *articleRepository) filtered(filter repository.ArticleFilter) []models.Article {
+	keyword := strings.ToLower(filter.Keyword)
+
+	var matched []models.Article
+	for _, article := range r.store.articles {
+		if filter.Status != nil && article.Status != *filter.Status {
+			continue
+		}
+		if filter.CategoryID != nil && article.CategoryID != *filter.CategoryID {
+			continue
+		}
+		if filter.AuthorID != nil && article.AuthorID != *filter.AuthorID {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(article.Title), keyword) {
+			continue
+		}
+		if !inRange(article.CreatedAt, filter.CreatedFrom, filter.CreatedTo) ||
+			!inRange(article.UpdatedAt, filter.UpdatedFrom, filter.UpdatedTo) {
+			continue
+		}
+		if len(filter.TagIDs) > 0 && !hasTags(article.Tags, filter.TagIDs, filter.MatchAllTags) {
+			continue
+		}
+		matched = append(matched, article)
+	}
+	return matched
+}
+
+// inRange 判断时间是否在 [from, to) 范围内，nil 表示不限制
+func inRange(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+}
+
+// hasTags 判断文章是否包含任一（all 为 true 时全部）标签
+func hasTags(tags []models.Tag, ids []uint, all bool) bool {
+	for _, id := range ids {
+		found := slices.ContainsFunc(tags, func(tag models.Tag) bool { return tag.ID == id })
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
+
+// compareKeys 按列表顺序比较排序键，a 排在 b 之前时返回负数
+func compareKeys(columns []repository.SortColumn, a, b repository.ArticleKey) int {
+	for _, column := range columns {
+		c := compareValues(a.Value(column.Name), b.Value(column.Name))
+		if column.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareValues 比较排序列的值，false 排在 true 之前
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case bool:
+		if a == b.(bool) {
+			return 0
+		}
+		if a {
+			return 1
+		}
+		return -1
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case int:
+		return cmp.Compare(a, b.(int))
+	case uint:
+		return cmp.Compare(a, b.(uint))
+	}
+	return 0
+}
+
+func (r *articleRepository) Update(ctx context.Context, id uint, article *models.Article) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.articles[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	// 与 GORM 的 Updates 一致，只更新非零值字段
+	if article.Title != "" {
+		existing.Title = article.Title
+	}
+	if article.Description != "" {
+		existing.Description = article.Description
+	}
+	if article.Content != "" {
+		existing.Content = article.Content
+	}
+	if article.Cover != "" {
+		existing.Cover = article.Cover
+	}
+	if article.AuthorID != 0 {
+		existing.AuthorID = article.AuthorID
+	}
+	if article.CategoryID != 0 {
+		existing.CategoryID = article.CategoryID
+	}
+	if article.Status != 0 {
+		existing.Status = article.Status
+	}
+	if article.IsTop {
+		existing.IsTop = article.IsTop
+	}
+
+	existing.UpdatedAt = r.store.now()
+	r.store.articles[id] = existing
+	return nil
+}
+
+func (r *articleRepository) ReplaceTags(ctx context.Context, id uint, tags []models.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	article, ok := r.store.articles[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	article.Tags = tagRefs(tags)
+	r.store.articles[id] = article
+	return nil
+}
+
+func (r *articleRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.articles[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.store.articles, id)
+	return nil
+}
+
+func (r *articleRepository) IncrementViewCount(ctx context.Context, id uint) error {
+	return r.increment(id, func(a *models.Article) { a.ViewCount++ })
+}
+
+func (r *articleRepository) IncrementLikeCount(ctx context.Context, id uint) error {
+	return r.increment(id, func(a *models.Article) { a.LikeCount++ })
+}
+
+func (r *articleRepository) CountByCategory(ctx context.Context, categoryID uint) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, article := range r.store.articles {
+		if article.CategoryID == categoryID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// increment 更新计数字段，与数据库实现一致，文章不存在时不报错
+func (r *articleRepository) increment(id uint, fn func(*models.Article)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if article, ok := r.store.articles[id]; ok {
+		fn(&article)
+		r.store.articles[id] = article
+	}
+	return nil
+}
+
+// hydrate 填充作者、分类和标签，调用方需持有读锁
+func (r *articleRepository) hydrate(article models.Article) models.Article {
+	article.Author = r.store.users[article.AuthorID]
+	article.Category = r.store.categories[article.CategoryID]
+
+	tags := make([]models.Tag, 0, len(article.Tags))
+	for _, ref := range article.Tags {
+		if tag, ok := r.store.tags[ref.ID]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	article.Tags = tags
+	return article
+}
+
+// stripArticle 去掉关联数据，标签只保留ID
+func stripArticle(article models.Article) models.Article {
+	article.Author = models.User{}
+	article.Category = models.Category{}
+	article.Comments = nil
+	article.Tags = tagRefs(article.Tags)
+	return article
+}
+
+// tagRefs 复制标签ID列表
+func tagRefs(tags []models.Tag) []models.Tag {
+	refs := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		refs = append(refs, models.Tag{BaseModel: models.BaseModel{ID: tag.ID}})
+	}
+	return refs
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// categoryRepository 分类仓储
+type categoryRepository struct {
+	store *Store
+}
+
+// NewCategoryRepository 创建分类仓储
+func NewCategoryRepository(store *Store) repository.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.stamp("categories", &category.BaseModel)
+	r.store.categories[category.ID] = stripCategory(*category)
+	return nil
+}
+
+func (r *categoryRepository) FindByID(ctx context.Context, id uint) (*models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	category, ok := r.store.categories[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) List(ctx context.Context) ([]models.Category, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	categories := make([]models.Category, 0, len(r.store.categories))
+	for _, category := range r.store.categories {
+		categories = append(categories, category)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Sort != categories[j].Sort {
+			return categories[i].Sort < categories[j].Sort
+		}
+		return categories[i].ID > categories[j].ID
+	})
+	return categories, nil
+}
+
+func (r *categoryRepository) ExistsByName(ctx context.Context, name string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, category := range r.store.categories {
+		if category.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.categories[category.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	existing.Name = category.Name
+	existing.Description = category.Description
+	existing.Sort = category.Sort
+	existing.UpdatedAt = r.store.now()
+	r.store.categories[category.ID] = existing
+	return nil
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.categories[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.store.categories, id)
+	return nil
+}
+
+// stripCategory 去掉关联数据，存储中只保存分类本身的字段
+func stripCategory(category models.Category) models.Category {
+	category.Articles = nil
+	return category
+}
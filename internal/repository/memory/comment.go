@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// commentRepository 评论仓储
+type commentRepository struct {
+	store *Store
+}
+
+// NewCommentRepository 创建评论仓储
+func NewCommentRepository(store *Store) repository.CommentRepository {
+	return &commentRepository{store: store}
+}
+
+func (r *commentRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.byUser(userID)
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	comments := paginate(matched, offset, limit)
+	for i := range comments {
+		article := r.store.articles[comments[i].ArticleID]
+		comments[i].Article = models.Article{BaseModel: models.BaseModel{ID: article.ID}, Title: article.Title}
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) CountByUser(ctx context.Context, userID uint) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.byUser(userID))), nil
+}
+
+// byUser 获取用户的评论，调用方需持有读锁
+func (r *commentRepository) byUser(userID uint) []models.Comment {
+	var matched []models.Comment
+	for _, comment := range r.store.comments {
+		if comment.UserID == userID {
+			matched = append(matched, comment)
+		}
+	}
+	return matched
+}
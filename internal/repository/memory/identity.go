@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// identityRepository 第三方登录身份仓储
+type identityRepository struct {
+	store *Store
+}
+
+// NewIdentityRepository 创建第三方登录身份仓储
+func NewIdentityRepository(store *Store) repository.IdentityRepository {
+	return &identityRepository{store: store}
+}
+
+func (r *identityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.identities {
+		if existing.Provider != identity.Provider {
+			continue
+		}
+		if existing.Subject == identity.Subject || existing.UserID == identity.UserID {
+			return repository.ErrDuplicate
+		}
+	}
+
+	r.store.nextID["user_identities"]++
+	identity.ID = r.store.nextID["user_identities"]
+	identity.CreatedAt = r.store.now()
+	r.store.identities[identity.ID] = *identity
+	return nil
+}
+
+func (r *identityRepository) FindBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, identity := range r.store.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *identityRepository) ListByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var identities []models.UserIdentity
+	for _, identity := range r.store.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].ID < identities[j].ID })
+	return identities, nil
+}
+
+func (r *identityRepository) Delete(ctx context.Context, userID uint, provider string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, identity := range r.store.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			delete(r.store.identities, id)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
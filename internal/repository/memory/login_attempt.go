@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// loginAttemptStore 登录失败计数和账号锁定存储
+type loginAttemptStore struct {
+	mu       sync.Mutex
+	failures map[string]failureCount
+	locks    map[string]time.Time // 用户名 -> 锁定到期时间
+	now      func() time.Time
+}
+
+type failureCount struct {
+	count     int
+	expiresAt time.Time
+}
+
+// NewLoginAttemptStore 创建登录失败计数和账号锁定存储
+func NewLoginAttemptStore() repository.LoginAttemptStore {
+	return &loginAttemptStore{
+		failures: make(map[string]failureCount),
+		locks:    make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (s *loginAttemptStore) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	f, ok := s.failures[key]
+	if !ok || !now.Before(f.expiresAt) {
+		f = failureCount{expiresAt: now.Add(window)}
+	}
+	f.count++
+	s.failures[key] = f
+	return f.count, nil
+}
+
+func (s *loginAttemptStore) Failures(ctx context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.failures[key]
+	if !ok || !s.now().Before(f.expiresAt) {
+		return 0, nil
+	}
+	return f.count, nil
+}
+
+func (s *loginAttemptStore) ClearFailures(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	return nil
+}
+
+func (s *loginAttemptStore) Lock(ctx context.Context, username string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[username] = until
+	return nil
+}
+
+func (s *loginAttemptStore) LockedUntil(ctx context.Context, username string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.locks[username]
+	if !ok || !s.now().Before(until) {
+		return time.Time{}, repository.ErrNotFound
+	}
+	return until, nil
+}
+
+func (s *loginAttemptStore) Unlock(ctx context.Context, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.locks[username]
+	delete(s.locks, username)
+	if !ok || !s.now().Before(until) {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (s *loginAttemptStore) ListLocks(ctx context.Context) ([]repository.LoginLock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	locks := make([]repository.LoginLock, 0, len(s.locks))
+	for username, until := range s.locks {
+		if !now.Before(until) {
+			delete(s.locks, username)
+			continue
+		}
+		locks = append(locks, repository.LoginLock{Username: username, LockedUntil: until})
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].LockedUntil.Before(locks[j].LockedUntil) })
+	return locks, nil
+}
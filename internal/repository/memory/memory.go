@@ -0,0 +1,120 @@
+// Package memory 基于内存的仓储实现，用于单元测试和本地调试
+// 数据只保存在进程内，不支持软删除恢复
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// Store 内存数据存储，多个仓储共享同一个 Store 以支持关联查询
+type Store struct {
+	mu         sync.RWMutex
+	users      map[uint]models.User
+	articles   map[uint]models.Article
+	categories map[uint]models.Category
+	tags       map[uint]models.Tag
+	comments   map[uint]models.Comment
+	recovery   map[uint]models.RecoveryCode
+	identities map[uint]models.UserIdentity
+	nextID     map[string]uint // 按表分配自增ID
+	now        func() time.Time
+}
+
+// NewStore 创建内存数据存储
+func NewStore() *Store {
+	return &Store{
+		users:      make(map[uint]models.User),
+		articles:   make(map[uint]models.Article),
+		categories: make(map[uint]models.Category),
+		tags:       make(map[uint]models.Tag),
+		comments:   make(map[uint]models.Comment),
+		recovery:   make(map[uint]models.RecoveryCode),
+		identities: make(map[uint]models.UserIdentity),
+		nextID:     make(map[string]uint),
+		now:        time.Now,
+	}
+}
+
+// NewRepositories 创建基于内存的仓储集合
+func NewRepositories() *repository.Repositories {
+	return NewStore().Repositories()
+}
+
+// Repositories 创建共享当前存储的仓储集合
+func (s *Store) Repositories() *repository.Repositories {
+	repos := &repository.Repositories{
+		Users:      NewUserRepository(s),
+		Articles:   NewArticleRepository(s),
+		Categories: NewCategoryRepository(s),
+		Tags:       NewTagRepository(s),
+		Comments:   NewCommentRepository(s),
+
+		RecoveryCodes:  NewRecoveryCodeRepository(s),
+		Identities:     NewIdentityRepository(s),
+		PasswordResets: NewPasswordResetStore(),
+		LoginAttempts:  NewLoginAttemptStore(),
+		OAuthStates:    NewOAuthStateStore(),
+	}
+	repos.UnitOfWork = NewUnitOfWork(s, repos)
+	return repos
+}
+
+// AddTag 添加标签，供测试准备数据使用
+func (s *Store) AddTag(name string) models.Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag := models.Tag{Name: name}
+	s.stamp("tags", &tag.BaseModel)
+	s.tags[tag.ID] = tag
+	return tag
+}
+
+// AddComment 添加评论并更新文章的评论数，供测试准备数据使用
+func (s *Store) AddComment(comment models.Comment) models.Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stamp("comments", &comment.BaseModel)
+	comment.Article = models.Article{}
+	comment.User = models.User{}
+	s.comments[comment.ID] = comment
+
+	if article, ok := s.articles[comment.ArticleID]; ok {
+		article.CommentCount++
+		s.articles[comment.ArticleID] = article
+	}
+	return comment
+}
+
+// paginate 截取分页范围内的记录
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset > len(items) {
+		offset = len(items)
+	}
+	end := len(items)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
+
+// stamp 为新记录分配ID和时间戳，调用方需持有写锁
+func (s *Store) stamp(table string, m *models.BaseModel) {
+	if m.ID == 0 {
+		s.nextID[table]++
+		m.ID = s.nextID[table]
+	} else if m.ID > s.nextID[table] {
+		s.nextID[table] = m.ID
+	}
+
+	now := s.now()
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
+	m.UpdatedAt = now
+}
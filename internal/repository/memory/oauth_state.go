@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// oauthStateStore 第三方登录授权请求存储
+type oauthStateStore struct {
+	mu     sync.Mutex
+	states map[string]oauthState // state 哈希 -> 授权请求
+}
+
+type oauthState struct {
+	state     repository.OAuthState
+	expiresAt time.Time
+}
+
+// NewOAuthStateStore 创建第三方登录授权请求存储
+func NewOAuthStateStore() repository.OAuthStateStore {
+	return &oauthStateStore{states: make(map[string]oauthState)}
+}
+
+func (s *oauthStateStore) Save(ctx context.Context, stateHash string, state repository.OAuthState, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 顺便清理已过期但未回调的请求
+	now := time.Now()
+	for hash, st := range s.states {
+		if now.After(st.expiresAt) {
+			delete(s.states, hash)
+		}
+	}
+	s.states[stateHash] = oauthState{state: state, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *oauthStateStore) Consume(ctx context.Context, stateHash string) (*repository.OAuthState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.states[stateHash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	delete(s.states, stateHash)
+
+	if time.Now().After(st.expiresAt) {
+		return nil, repository.ErrNotFound
+	}
+	return &st.state, nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// passwordResetStore 密码重置令牌存储
+type passwordResetStore struct {
+	mu      sync.Mutex
+	tokens  map[string]resetToken // 令牌哈希 -> 令牌
+	current map[uint]string       // 用户ID -> 最新的令牌哈希
+}
+
+type resetToken struct {
+	userID    uint
+	expiresAt time.Time
+}
+
+// NewPasswordResetStore 创建密码重置令牌存储
+func NewPasswordResetStore() repository.PasswordResetStore {
+	return &passwordResetStore{
+		tokens:  make(map[string]resetToken),
+		current: make(map[uint]string),
+	}
+}
+
+func (s *passwordResetStore) Save(ctx context.Context, userID uint, tokenHash string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.current[userID]; ok {
+		delete(s.tokens, old)
+	}
+	s.tokens[tokenHash] = resetToken{userID: userID, expiresAt: time.Now().Add(ttl)}
+	s.current[userID] = tokenHash
+	return nil
+}
+
+func (s *passwordResetStore) Consume(ctx context.Context, tokenHash string) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	delete(s.tokens, tokenHash)
+	if s.current[token.userID] == tokenHash {
+		delete(s.current, token.userID)
+	}
+
+	if time.Now().After(token.expiresAt) {
+		return 0, repository.ErrNotFound
+	}
+	return token.userID, nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+func TestPasswordResetStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewPasswordResetStore()
+
+	if err := s.Save(ctx, 1, "older", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(ctx, 1, "newer", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(ctx, 2, "other", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新令牌使同一用户的旧令牌失效，不影响其他用户
+	if _, err := s.Consume(ctx, "older"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Consume(older) error = %v, want %v", err, repository.ErrNotFound)
+	}
+	if id, err := s.Consume(ctx, "other"); err != nil || id != 2 {
+		t.Errorf("Consume(other) = %d, %v, want 2", id, err)
+	}
+
+	// 令牌只能使用一次
+	if id, err := s.Consume(ctx, "newer"); err != nil || id != 1 {
+		t.Errorf("Consume(newer) = %d, %v, want 1", id, err)
+	}
+	if _, err := s.Consume(ctx, "newer"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Consume(newer) twice error = %v, want %v", err, repository.ErrNotFound)
+	}
+}
+
+func TestPasswordResetStoreExpired(t *testing.T) {
+	ctx := context.Background()
+	s := NewPasswordResetStore()
+
+	if err := s.Save(ctx, 1, "expired", -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Consume(ctx, "expired"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Consume(expired) error = %v, want %v", err, repository.ErrNotFound)
+	}
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// recoveryCodeRepository 两步验证恢复码仓储
+type recoveryCodeRepository struct {
+	store *Store
+}
+
+// NewRecoveryCodeRepository 创建两步验证恢复码仓储
+func NewRecoveryCodeRepository(store *Store) repository.RecoveryCodeRepository {
+	return &recoveryCodeRepository{store: store}
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID uint, hashes []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.deleteByUser(userID)
+	for _, hash := range hashes {
+		r.store.nextID["user_recovery_codes"]++
+		id := r.store.nextID["user_recovery_codes"]
+		r.store.recovery[id] = models.RecoveryCode{ID: id, CreatedAt: r.store.now(), UserID: userID, CodeHash: hash}
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID uint, hash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, code := range r.store.recovery {
+		if code.UserID == userID && code.CodeHash == hash && code.UsedAt == nil {
+			now := r.store.now()
+			code.UsedAt = &now
+			r.store.recovery[id] = code
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *recoveryCodeRepository) CountUnused(ctx context.Context, userID uint) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, code := range r.store.recovery {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUser(ctx context.Context, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.deleteByUser(userID)
+	return nil
+}
+
+// deleteByUser 删除用户的全部恢复码，调用方需持有写锁
+func (r *recoveryCodeRepository) deleteByUser(userID uint) {
+	for id, code := range r.store.recovery {
+		if code.UserID == userID {
+			delete(r.store.recovery, id)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// tagRepository 标签仓储
+type tagRepository struct {
+	store *Store
+}
+
+// NewTagRepository 创建标签仓储
+func NewTagRepository(store *Store) repository.TagRepository {
+	return &tagRepository{store: store}
+}
+
+func (r *tagRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var tags []models.Tag
+	for _, id := range ids {
+		if tag, ok := r.store.tags[id]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
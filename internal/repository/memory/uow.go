@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/database"
+)
+
+// unitOfWork 内存工作单元
+// 执行前保存快照，失败时整体恢复；不提供事务隔离，并发写入时仅适用于测试
+type unitOfWork struct {
+	store *Store
+	repos *repository.Repositories
+}
+
+// NewUnitOfWork 创建工作单元
+func NewUnitOfWork(store *Store, repos *repository.Repositories) repository.UnitOfWork {
+	return &unitOfWork{store: store, repos: repos}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context, repos *repository.Repositories) error) error {
+	// 已在事务中，直接加入外层事务
+	if repository.InTx(ctx) {
+		return fn(ctx, u.repos)
+	}
+
+	// 与数据库实现一致，遇到瞬时错误时整体重试
+	for attempt := 1; ; attempt++ {
+		txCtx, hooks := repository.WithCommitHooks(ctx)
+		snap := u.store.snapshot()
+		err := fn(txCtx, u.repos)
+		if err == nil {
+			hooks.Run()
+			return nil
+		}
+
+		u.store.restore(snap)
+		if !database.IsRetryable(err) || attempt >= database.MaxTxAttempts {
+			return err
+		}
+	}
+}
+
+// storeSnapshot 存储快照
+type storeSnapshot struct {
+	users      map[uint]models.User
+	articles   map[uint]models.Article
+	categories map[uint]models.Category
+	tags       map[uint]models.Tag
+	comments   map[uint]models.Comment
+	recovery   map[uint]models.RecoveryCode
+	identities map[uint]models.UserIdentity
+	nextID     map[string]uint
+}
+
+// snapshot 复制当前数据
+func (s *Store) snapshot() storeSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return storeSnapshot{
+		users:      copyMap(s.users),
+		articles:   copyMap(s.articles),
+		categories: copyMap(s.categories),
+		tags:       copyMap(s.tags),
+		comments:   copyMap(s.comments),
+		recovery:   copyMap(s.recovery),
+		identities: copyMap(s.identities),
+		nextID:     copyMap(s.nextID),
+	}
+}
+
+// restore 恢复到快照时的数据
+func (s *Store) restore(snap storeSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = snap.users
+	s.articles = snap.articles
+	s.categories = snap.categories
+	s.tags = snap.tags
+	s.comments = snap.comments
+	s.recovery = snap.recovery
+	s.identities = snap.identities
+	s.nextID = snap.nextID
+}
+
+// copyMap 浅拷贝 map，值类型的记录互不影响
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
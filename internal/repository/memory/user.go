@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// userRepository 用户仓储
+type userRepository struct {
+	store *Store
+}
+
+// NewUserRepository 创建用户仓储
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.duplicate(0, user.Username, user.Email) {
+		return repository.ErrDuplicate
+	}
+
+	r.store.stamp("users", &user.BaseModel)
+	r.store.users[user.ID] = stripUser(*user)
+	return nil
+}
+
+// duplicate 判断用户名或邮箱是否已被其他用户使用，调用方需持有锁
+func (r *userRepository) duplicate(id uint, username, email string) bool {
+	for _, user := range r.store.users {
+		if user.ID == id {
+			continue
+		}
+		if user.Username == username || (email != "" && user.Email == email) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if email != "" && user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *userRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	_, err := r.FindByUsername(ctx, username)
+	if err == repository.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, id uint, update repository.UserUpdate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+
+	if update.Nickname != nil {
+		user.Nickname = *update.Nickname
+	}
+	if update.Email != nil {
+		if r.duplicate(id, "", *update.Email) {
+			return repository.ErrDuplicate
+		}
+		user.Email = *update.Email
+	}
+	if update.Avatar != nil {
+		user.Avatar = *update.Avatar
+	}
+	if update.Password != nil {
+		user.Password = *update.Password
+	}
+	if update.Role != nil {
+		user.Role = *update.Role
+	}
+	if update.Status != nil {
+		user.Status = *update.Status
+	}
+	if update.Locale != nil {
+		user.Locale = *update.Locale
+	}
+	if update.BanReason != nil {
+		user.BanReason = *update.BanReason
+	}
+	if update.BannedUntil != nil {
+		user.BannedUntil = nil
+		if !update.BannedUntil.IsZero() {
+			until := *update.BannedUntil
+			user.BannedUntil = &until
+		}
+	}
+
+	if update.PendingEmail != nil {
+		user.PendingEmail = *update.PendingEmail
+	}
+	if update.EmailVerifiedAt != nil {
+		user.EmailVerifiedAt = nil
+		if !update.EmailVerifiedAt.IsZero() {
+			verifiedAt := *update.EmailVerifiedAt
+			user.EmailVerifiedAt = &verifiedAt
+		}
+	}
+
+	if update.TOTPSecret != nil {
+		user.TOTPSecret = *update.TOTPSecret
+	}
+	if update.TOTPEnabledAt != nil {
+		user.TOTPEnabledAt = nil
+		if !update.TOTPEnabledAt.IsZero() {
+			enabledAt := *update.TOTPEnabledAt
+			user.TOTPEnabledAt = &enabledAt
+		}
+	}
+
+	user.UpdatedAt = r.store.now()
+	r.store.users[id] = user
+	return nil
+}
+
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	user.TokenVersion++
+	r.store.users[id] = user
+	return nil
+}
+
+func (r *userRepository) UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok || step <= user.TOTPLastStep {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	r.store.users[id] = user
+	return true, nil
+}
+
+func (r *userRepository) Search(ctx context.Context, filter repository.UserFilter, offset, limit int) ([]models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	matched := r.filtered(filter)
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	return paginate(matched, offset, limit), nil
+}
+
+func (r *userRepository) Count(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.filtered(filter))), nil
+}
+
+// filtered 获取符合搜索条件的用户，调用方需持有读锁
+func (r *userRepository) filtered(filter repository.UserFilter) []models.User {
+	username, email := strings.ToLower(filter.Username), strings.ToLower(filter.Email)
+
+	var matched []models.User
+	for _, user := range r.store.users {
+		if username != "" && !strings.Contains(strings.ToLower(user.Username), username) {
+			continue
+		}
+		if email != "" && !strings.Contains(strings.ToLower(user.Email), email) {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.Status != nil && user.Status != *filter.Status {
+			continue
+		}
+		matched = append(matched, user)
+	}
+	return matched
+}
+
+// stripUser 去掉关联数据，存储中只保存用户本身的字段
+func stripUser(user models.User) models.User {
+	user.Articles = nil
+	user.Comments = nil
+	return user
+}
@@ -0,0 +1,113 @@
+package redisrepo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// 登录保护的键
+// login:fail:<key> 为失败次数，login:lock:<用户名> 为锁定到期时间（Unix 毫秒），
+// login:locks 有序集合以到期时间为分值记录全部锁定，用于管理员查看
+const (
+	loginFailPrefix = "login:fail:"
+	loginLockPrefix = "login:lock:"
+	loginLocksKey   = "login:locks"
+)
+
+// loginAttemptStore 登录失败计数和账号锁定存储
+type loginAttemptStore struct {
+	client *redis.Client
+}
+
+// NewLoginAttemptStore 创建登录失败计数和账号锁定存储
+func NewLoginAttemptStore(client *redis.Client) repository.LoginAttemptStore {
+	return &loginAttemptStore{client: client}
+}
+
+func (s *loginAttemptStore) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	key = loginFailPrefix + key
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// 计数不存在时先以过期时间创建，INCR 不改变过期时间，窗口从第一次失败开始计算
+		pipe.SetNX(ctx, key, 0, window)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+func (s *loginAttemptStore) Failures(ctx context.Context, key string) (int, error) {
+	n, err := s.client.Get(ctx, loginFailPrefix+key).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return n, err
+}
+
+func (s *loginAttemptStore) ClearFailures(ctx context.Context, key string) error {
+	return s.client.Del(ctx, loginFailPrefix+key).Err()
+}
+
+func (s *loginAttemptStore) Lock(ctx context.Context, username string, until time.Time) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, loginLockPrefix+username, until.UnixMilli(), time.Until(until))
+		pipe.ZAdd(ctx, loginLocksKey, &redis.Z{Score: float64(until.UnixMilli()), Member: username})
+		return nil
+	})
+	return err
+}
+
+func (s *loginAttemptStore) LockedUntil(ctx context.Context, username string) (time.Time, error) {
+	ms, err := s.client.Get(ctx, loginLockPrefix+username).Int64()
+	if err == redis.Nil {
+		return time.Time{}, repository.ErrNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(ms), nil
+}
+
+func (s *loginAttemptStore) Unlock(ctx context.Context, username string) error {
+	var del *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		del = pipe.Del(ctx, loginLockPrefix+username)
+		pipe.ZRem(ctx, loginLocksKey, username)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if del.Val() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (s *loginAttemptStore) ListLocks(ctx context.Context) ([]repository.LoginLock, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if err := s.client.ZRemRangeByScore(ctx, loginLocksKey, "-inf", now).Err(); err != nil {
+		return nil, err
+	}
+
+	members, err := s.client.ZRangeWithScores(ctx, loginLocksKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	locks := make([]repository.LoginLock, 0, len(members))
+	for _, m := range members {
+		locks = append(locks, repository.LoginLock{
+			Username:    m.Member.(string),
+			LockedUntil: time.UnixMilli(int64(m.Score)),
+		})
+	}
+	return locks, nil
+}
@@ -0,0 +1,54 @@
+package redisrepo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// oauthStatePrefix 第三方登录授权请求的键，oauth:state:<state 哈希> 保存 JSON 编码的授权请求
+const oauthStatePrefix = "oauth:state:"
+
+// oauthStateStore 第三方登录授权请求存储
+type oauthStateStore struct {
+	client *redis.Client
+}
+
+// NewOAuthStateStore 创建第三方登录授权请求存储
+func NewOAuthStateStore(client *redis.Client) repository.OAuthStateStore {
+	return &oauthStateStore{client: client}
+}
+
+func (s *oauthStateStore) Save(ctx context.Context, stateHash string, state repository.OAuthState, ttl time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, oauthStatePrefix+stateHash, data, ttl).Err()
+}
+
+func (s *oauthStateStore) Consume(ctx context.Context, stateHash string) (*repository.OAuthState, error) {
+	// GET 和 DEL 在同一事务中执行，同一 state 只能回调一次
+	var get *redis.StringCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, oauthStatePrefix+stateHash)
+		pipe.Del(ctx, oauthStatePrefix+stateHash)
+		return nil
+	})
+	if err == redis.Nil {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state repository.OAuthState
+	if err := json.Unmarshal([]byte(get.Val()), &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,74 @@
+package redisrepo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// 密码重置令牌的键
+// password_reset:token:<令牌哈希> 保存用户ID，password_reset:user:<用户ID> 保存该用户最新的令牌哈希
+const (
+	resetTokenPrefix = "password_reset:token:"
+	resetUserPrefix  = "password_reset:user:"
+)
+
+// passwordResetStore 密码重置令牌存储
+type passwordResetStore struct {
+	client *redis.Client
+}
+
+// NewPasswordResetStore 创建密码重置令牌存储
+func NewPasswordResetStore(client *redis.Client) repository.PasswordResetStore {
+	return &passwordResetStore{client: client}
+}
+
+func (s *passwordResetStore) Save(ctx context.Context, userID uint, tokenHash string, ttl time.Duration) error {
+	userKey := resetUserPrefix + strconv.FormatUint(uint64(userID), 10)
+
+	old, err := s.client.Get(ctx, userKey).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if old != "" {
+			pipe.Del(ctx, resetTokenPrefix+old)
+		}
+		pipe.Set(ctx, resetTokenPrefix+tokenHash, userID, ttl)
+		pipe.Set(ctx, userKey, tokenHash, ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *passwordResetStore) Consume(ctx context.Context, tokenHash string) (uint, error) {
+	// GET 和 DEL 在同一事务中执行，并发使用同一令牌时只有一个请求能取到
+	var get *redis.StringCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, resetTokenPrefix+tokenHash)
+		pipe.Del(ctx, resetTokenPrefix+tokenHash)
+		return nil
+	})
+	if err == redis.Nil {
+		return 0, repository.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := get.Uint64()
+	if err != nil {
+		return 0, err
+	}
+
+	userKey := resetUserPrefix + strconv.FormatUint(userID, 10)
+	if current, err := s.client.Get(ctx, userKey).Result(); err == nil && current == tokenHash {
+		s.client.Del(ctx, userKey)
+	}
+	return uint(userID), nil
+}
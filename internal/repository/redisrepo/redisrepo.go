@@ -0,0 +1,16 @@
+// Package redisrepo 基于 Redis 的存储实现，用于令牌等有过期时间的临时数据
+package redisrepo
+
+import (
+	"github.com/go-redis/redis/v8"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// Attach 为仓储集合设置基于 Redis 的存储
+func Attach(repos *repository.Repositories, client *redis.Client) *repository.Repositories {
+	repos.PasswordResets = NewPasswordResetStore(client)
+	repos.LoginAttempts = NewLoginAttemptStore(client)
+	repos.OAuthStates = NewOAuthStateStore(client)
+	return repos
+}
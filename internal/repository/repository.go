@@ -0,0 +1,303 @@
+// Package repository 定义数据访问接口，服务层只依赖这些接口，
+// 具体实现见 gormrepo（数据库）、redisrepo（Redis 中的临时数据）和 memory（内存，用于测试）
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("记录不存在")
+
+// ErrDuplicate 违反唯一约束
+var ErrDuplicate = errors.New("记录已存在")
+
+// Repositories 仓储集合
+type Repositories struct {
+	Users      UserRepository
+	Articles   ArticleRepository
+	Categories CategoryRepository
+	Tags       TagRepository
+	Comments   CommentRepository
+	UnitOfWork UnitOfWork
+
+	RecoveryCodes RecoveryCodeRepository
+	Identities    IdentityRepository
+
+	PasswordResets PasswordResetStore
+	LoginAttempts  LoginAttemptStore
+	OAuthStates    OAuthStateStore
+}
+
+// UserUpdate 用户可更新字段，nil 表示不修改
+type UserUpdate struct {
+	Nickname *string
+	Email    *string
+	Avatar   *string
+	Password *string
+	Role     *string
+	Status   *int
+	Locale   *string
+
+	BanReason   *string
+	BannedUntil *time.Time // 零值表示清除封禁到期时间
+
+	PendingEmail    *string
+	EmailVerifiedAt *time.Time // 零值表示清除验证时间
+
+	TOTPSecret    *string
+	TOTPEnabledAt *time.Time // 零值表示清除启用时间
+}
+
+// UserFilter 用户搜索条件，用户名和邮箱为模糊匹配
+type UserFilter struct {
+	Username string
+	Email    string
+	Role     string
+	Status   *int
+}
+
+// UserRepository 用户仓储
+type UserRepository interface {
+	// Create 创建用户，用户名或邮箱重复时返回 ErrDuplicate
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	// Update 更新用户字段，用户不存在时返回 ErrNotFound，邮箱重复时返回 ErrDuplicate
+	Update(ctx context.Context, id uint, update UserUpdate) error
+	// IncrementTokenVersion 递增令牌版本，使已签发的令牌失效，用户不存在时返回 ErrNotFound
+	IncrementTokenVersion(ctx context.Context, id uint) error
+	// UseTOTPStep 记录通过验证的动态码时间步，step 不大于已记录的时间步时返回 false，表示动态码已使用过
+	UseTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
+	// Search 按ID倒序搜索用户
+	Search(ctx context.Context, filter UserFilter, offset, limit int) ([]models.User, error)
+	Count(ctx context.Context, filter UserFilter) (int64, error)
+}
+
+// ArticleFilter 文章列表筛选条件，时间范围包含下界、不包含上界
+type ArticleFilter struct {
+	Status       *int
+	CategoryID   *uint
+	AuthorID     *uint
+	TagIDs       []uint
+	MatchAllTags bool   // true 时需包含全部标签，否则包含任一标签即可
+	Keyword      string // 标题关键字，不区分大小写
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+}
+
+// 文章列表可排序的字段，值为数据库列名
+const (
+	ArticleSortCreatedAt = "created_at"
+	ArticleSortUpdatedAt = "updated_at"
+	ArticleSortViews     = "view_count"
+	ArticleSortLikes     = "like_count"
+	ArticleSortComments  = "comment_count"
+)
+
+// ArticleSort 文章列表排序方式，零值为默认排序：置顶优先，再按创建时间倒序
+type ArticleSort struct {
+	Field string // ArticleSort* 常量之一
+	Desc  bool
+}
+
+// SortColumn 排序列
+type SortColumn struct {
+	Name string
+	Desc bool
+}
+
+// Columns 排序依次使用的列，最后一列始终为 id 以保证顺序唯一
+// 未知的字段按默认排序处理，返回的列名都是常量，可以直接用于 SQL
+func (s ArticleSort) Columns() []SortColumn {
+	switch s.Field {
+	case ArticleSortCreatedAt, ArticleSortUpdatedAt, ArticleSortViews, ArticleSortLikes, ArticleSortComments:
+		return []SortColumn{{Name: s.Field, Desc: s.Desc}, {Name: "id", Desc: s.Desc}}
+	}
+	return []SortColumn{{Name: "is_top", Desc: true}, {Name: "created_at", Desc: true}, {Name: "id", Desc: true}}
+}
+
+// ArticleKey 文章在列表中的排序键，包含所有可排序的列
+type ArticleKey struct {
+	IsTop        bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ViewCount    int
+	LikeCount    int
+	CommentCount int
+	ID           uint
+}
+
+// KeyOf 获取文章的排序键
+func KeyOf(article *models.Article) ArticleKey {
+	return ArticleKey{
+		IsTop:        article.IsTop,
+		CreatedAt:    article.CreatedAt,
+		UpdatedAt:    article.UpdatedAt,
+		ViewCount:    article.ViewCount,
+		LikeCount:    article.LikeCount,
+		CommentCount: article.CommentCount,
+		ID:           article.ID,
+	}
+}
+
+// Value 获取排序列对应的值
+func (k ArticleKey) Value(column string) interface{} {
+	switch column {
+	case "is_top":
+		return k.IsTop
+	case ArticleSortCreatedAt:
+		return k.CreatedAt
+	case ArticleSortUpdatedAt:
+		return k.UpdatedAt
+	case ArticleSortViews:
+		return k.ViewCount
+	case ArticleSortLikes:
+		return k.LikeCount
+	case ArticleSortComments:
+		return k.CommentCount
+	}
+	return k.ID
+}
+
+// ArticleSelect 文章列表需要的列和关联
+type ArticleSelect struct {
+	Columns  []string // 数据库列名，为空时查询全部列
+	Author   bool
+	Category bool
+	Tags     bool
+}
+
+// ArticlePage 文章列表分页条件，After 和 Before 用于游标分页，不能与 Offset 同时使用
+type ArticlePage struct {
+	Offset int
+	Limit  int
+	Sort   ArticleSort
+	After  *ArticleKey    // 只返回排在该键之后的文章
+	Before *ArticleKey    // 只返回排在该键之前且最靠近它的文章，结果仍按列表顺序排列
+	Select *ArticleSelect // 为 nil 时查询全部列和关联
+}
+
+// ArticleRepository 文章仓储
+type ArticleRepository interface {
+	Create(ctx context.Context, article *models.Article) error
+	// FindByID 获取文章，包含作者的公开信息、分类和标签
+	FindByID(ctx context.Context, id uint) (*models.Article, error)
+	// List 按 page.Sort 排序获取文章列表
+	List(ctx context.Context, filter ArticleFilter, page ArticlePage) ([]models.Article, error)
+	Count(ctx context.Context, filter ArticleFilter) (int64, error)
+	// Update 更新文章的非零值字段，不处理标签
+	Update(ctx context.Context, id uint, article *models.Article) error
+	ReplaceTags(ctx context.Context, id uint, tags []models.Tag) error
+	Delete(ctx context.Context, id uint) error
+	IncrementViewCount(ctx context.Context, id uint) error
+	IncrementLikeCount(ctx context.Context, id uint) error
+	CountByCategory(ctx context.Context, categoryID uint) (int64, error)
+}
+
+// CategoryRepository 分类仓储
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	FindByID(ctx context.Context, id uint) (*models.Category, error)
+	// List 按排序值升序、ID倒序获取全部分类
+	List(ctx context.Context) ([]models.Category, error)
+	ExistsByName(ctx context.Context, name string) (bool, error)
+	// Update 更新分类的名称、描述和排序值
+	Update(ctx context.Context, category *models.Category) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// CommentRepository 评论仓储
+type CommentRepository interface {
+	// ListByUser 按ID倒序获取用户的评论，包含所属文章的ID和标题
+	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]models.Comment, error)
+	CountByUser(ctx context.Context, userID uint) (int64, error)
+}
+
+// TagRepository 标签仓储
+type TagRepository interface {
+	// FindByIDs 批量获取标签，不存在的ID会被忽略
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Tag, error)
+}
+
+// RecoveryCodeRepository 两步验证恢复码仓储
+type RecoveryCodeRepository interface {
+	// Replace 删除用户的全部恢复码并保存新的恢复码哈希
+	Replace(ctx context.Context, userID uint, hashes []string) error
+	// Use 将恢复码标记为已使用，不存在或已使用时返回 ErrNotFound
+	Use(ctx context.Context, userID uint, hash string) error
+	// CountUnused 用户未使用的恢复码数量
+	CountUnused(ctx context.Context, userID uint) (int64, error)
+	// DeleteByUser 删除用户的全部恢复码
+	DeleteByUser(ctx context.Context, userID uint) error
+}
+
+// IdentityRepository 第三方登录身份仓储
+type IdentityRepository interface {
+	// Create 关联身份，该身份已关联其他账号或用户已关联该身份提供方时返回 ErrDuplicate
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	// FindBySubject 根据身份提供方和用户标识查找，未关联时返回 ErrNotFound
+	FindBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	// ListByUser 用户关联的全部身份，按关联时间升序
+	ListByUser(ctx context.Context, userID uint) ([]models.UserIdentity, error)
+	// Delete 解除用户与身份提供方的关联，未关联时返回 ErrNotFound
+	Delete(ctx context.Context, userID uint, provider string) error
+}
+
+// PasswordResetStore 密码重置令牌存储，只保存令牌的哈希
+type PasswordResetStore interface {
+	// Save 保存令牌哈希，同一用户之前未使用的令牌失效
+	Save(ctx context.Context, userID uint, tokenHash string, ttl time.Duration) error
+	// Consume 取出并删除令牌对应的用户ID，令牌不存在或已过期时返回 ErrNotFound
+	Consume(ctx context.Context, tokenHash string) (uint, error)
+}
+
+// LoginLock 登录锁定记录
+type LoginLock struct {
+	Username    string    `json:"username"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// LoginAttemptStore 登录失败计数和账号锁定存储
+// key 由调用方区分维度，如 "user:<用户名>"、"ip:<地址>"
+type LoginAttemptStore interface {
+	// AddFailure 失败次数加一并返回当前次数，计数从第一次失败起 window 后清零
+	AddFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Failures 当前失败次数
+	Failures(ctx context.Context, key string) (int, error)
+	// ClearFailures 清零失败次数
+	ClearFailures(ctx context.Context, key string) error
+	// Lock 锁定用户名直到 until
+	Lock(ctx context.Context, username string, until time.Time) error
+	// LockedUntil 用户名的锁定到期时间，未锁定时返回 ErrNotFound
+	LockedUntil(ctx context.Context, username string) (time.Time, error)
+	// Unlock 解除锁定，未锁定时返回 ErrNotFound
+	Unlock(ctx context.Context, username string) error
+	// ListLocks 全部未到期的锁定，按到期时间升序
+	ListLocks(ctx context.Context) ([]LoginLock, error)
+}
+
+// OAuthState 第三方登录授权请求，回调时凭 state 取回
+type OAuthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"` // PKCE 校验码
+	Nonce        string `json:"nonce"`
+	UserID       uint   `json:"user_id,omitempty"` // 关联身份时为发起请求的用户，登录时为 0
+}
+
+// OAuthStateStore 第三方登录授权请求存储，只保存 state 的哈希
+type OAuthStateStore interface {
+	// Save 保存授权请求，ttl 后过期
+	Save(ctx context.Context, stateHash string, state OAuthState, ttl time.Duration) error
+	// Consume 取出并删除授权请求，不存在或已过期时返回 ErrNotFound
+	Consume(ctx context.Context, stateHash string) (*OAuthState, error)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+// UnitOfWork 工作单元，在同一事务中执行多个仓储操作
+type UnitOfWork interface {
+	// Do 在事务中执行 fn，fn 中必须使用传入的 ctx 和 repos
+	// fn 返回错误时回滚；提交成功后依次执行通过 AfterCommit 注册的回调
+	// 在已有事务中调用时加入外层事务
+	Do(ctx context.Context, fn func(ctx context.Context, repos *Repositories) error) error
+}
+
+// commitHooksKey 事务提交回调在 context 中的键
+type commitHooksKey struct{}
+
+// CommitHooks 事务提交后执行的回调，供 UnitOfWork 实现使用
+type CommitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// WithCommitHooks 为事务创建回调列表并放入 context
+func WithCommitHooks(ctx context.Context) (context.Context, *CommitHooks) {
+	hooks := &CommitHooks{}
+	return context.WithValue(ctx, commitHooksKey{}, hooks), hooks
+}
+
+// InTx 判断 context 是否处于工作单元中
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(commitHooksKey{}).(*CommitHooks)
+	return ok
+}
+
+// Run 依次执行回调，事务提交后调用
+func (h *CommitHooks) Run() {
+	h.mu.Lock()
+	fns := h.fns
+	h.fns = nil
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// AfterCommit 注册事务提交后执行的回调，用于缓存失效、索引更新等副作用
+// 事务回滚或重试时已注册的回调被丢弃；不在事务中时立即执行
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*CommitHooks)
+	if !ok {
+		fn()
+		return
+	}
+
+	hooks.mu.Lock()
+	hooks.fns = append(hooks.fns, fn)
+	hooks.mu.Unlock()
+}
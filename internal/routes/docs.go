@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/apidoc"
+	"github.com/xiaoxin/blog-backend/internal/controllers"
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/services"
+	"github.com/xiaoxin/blog-backend/internal/views"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// 接口文档地址
+const (
+	openAPIPath = "/api/openapi.json"
+	docsPath    = "/api/docs"
+)
+
+// apiDocs 路由文档表，新增路由时需要在这里登记，否则 openapi --check 会失败
+func apiDocs() []apidoc.Route {
+	return []apidoc.Route{
+		// 用户
+		{Method: http.MethodPost, Path: "/api/v1/register", Tag: "用户", Summary: "用户注册",
+			Body: controllers.RegisterRequest{}, Data: controllers.RegisterResponse{}, Errors: []int{http.StatusConflict}},
+		{Method: http.MethodPost, Path: "/api/v1/login", Tag: "用户", Summary: "用户登录",
+			Body: controllers.LoginRequest{}, Data: controllers.LoginResponse{},
+			Errors: []int{http.StatusUnauthorized, http.StatusForbidden},
+			Description: "连续失败会逐次延迟响应，次数过多时锁定账号；锁定期间返回与密码错误相同的 invalid_credentials。" +
+				"已启用两步验证时不返回令牌，而是返回 mfa_required 和 mfa_token，需调用 /login/2fa 完成登录"},
+		{Method: http.MethodPost, Path: "/api/v1/login/2fa", Tag: "用户", Summary: "两步验证登录",
+			Body: controllers.LoginTwoFactorRequest{}, Data: controllers.LoginResponse{},
+			Errors:      []int{http.StatusUnauthorized, http.StatusForbidden},
+			Description: "mfa_token 为登录接口返回的挑战令牌，5 分钟内有效；code 为六位动态码或恢复码，恢复码使用一次后失效。连续失败 5 次后需等待挑战令牌过期"},
+		{Method: http.MethodGet, Path: "/api/v1/user/profile", Tag: "用户", Summary: "获取当前用户信息", Auth: apidoc.AuthUser,
+			Data: models.User{}},
+		{Method: http.MethodPut, Path: "/api/v1/user/profile", Tag: "用户", Summary: "更新当前用户信息", Auth: apidoc.AuthUser,
+			Body: controllers.UpdateProfileRequest{}, Errors: []int{http.StatusConflict},
+			Description: "修改邮箱时新邮箱保存为 pending_email 并发送验证邮件，验证后才替换当前邮箱"},
+		{Method: http.MethodPut, Path: "/api/v1/user/password", Tag: "用户", Summary: "修改密码", Auth: apidoc.AuthUser,
+			Body: controllers.ChangePasswordRequest{}, Description: "修改成功后已签发的全部令牌（包括当前令牌）失效，需要重新登录"},
+		{Method: http.MethodGet, Path: "/api/v1/email/verify", Tag: "用户", Summary: "验证邮箱",
+			Query: controllers.VerifyEmailQuery{}, Description: "验证邮件中的链接，token 过期或邮箱已再次修改时返回 invalid_verification_token"},
+		{Method: http.MethodPost, Path: "/api/v1/user/email/verification", Tag: "用户", Summary: "重新发送验证邮件", Auth: apidoc.AuthUser,
+			Errors:      []int{http.StatusTooManyRequests},
+			Description: "有待验证的新邮箱时发往新邮箱，否则发往未验证的当前邮箱，每分钟最多发送一次"},
+		{Method: http.MethodGet, Path: "/api/v1/user/2fa", Tag: "两步验证", Summary: "获取两步验证状态", Auth: apidoc.AuthUser,
+			Data: controllers.TwoFactorStatusResponse{}},
+		{Method: http.MethodPost, Path: "/api/v1/user/2fa/setup", Tag: "两步验证", Summary: "生成两步验证密钥", Auth: apidoc.AuthUser,
+			Data: controllers.TwoFactorSetupResponse{}, Errors: []int{http.StatusNotFound},
+			Description: "返回 otpauth:// 地址和二维码，用验证器应用扫码后调用 /user/2fa/enable 确认；确认前重复调用会生成新的密钥"},
+		{Method: http.MethodPost, Path: "/api/v1/user/2fa/enable", Tag: "两步验证", Summary: "启用两步验证", Auth: apidoc.AuthUser,
+			Body: controllers.TwoFactorCodeRequest{}, Data: controllers.RecoveryCodesResponse{},
+			Description: "code 为验证器应用中的六位动态码，成功后返回 10 个恢复码，只显示这一次；已签发的全部令牌（包括当前令牌）失效，需要重新登录并完成两步验证"},
+		{Method: http.MethodPost, Path: "/api/v1/user/2fa/disable", Tag: "两步验证", Summary: "关闭两步验证", Auth: apidoc.AuthUser,
+			Body: controllers.DisableTwoFactorRequest{}, Description: "需要当前密码和动态码（或恢复码）"},
+		{Method: http.MethodPost, Path: "/api/v1/user/2fa/recovery-codes", Tag: "两步验证", Summary: "重新生成恢复码", Auth: apidoc.AuthUser,
+			Body: controllers.TwoFactorCodeRequest{}, Data: controllers.RecoveryCodesResponse{},
+			Description: "需要六位动态码，之前的恢复码全部失效"},
+		{Method: http.MethodPost, Path: "/api/v1/password/forgot", Tag: "用户", Summary: "找回密码",
+			Body: controllers.ForgotPasswordRequest{}, Errors: []int{http.StatusNotFound},
+			Description: "向邮箱发送重置密码链接，邮箱是否注册都返回相同的响应；同一用户每分钟最多发送一封"},
+		{Method: http.MethodPost, Path: "/api/v1/password/reset", Tag: "用户", Summary: "重置密码",
+			Body: controllers.ResetPasswordRequest{}, Errors: []int{http.StatusNotFound},
+			Description: "token 为重置链接中的参数，只能使用一次；重置成功后已签发的全部令牌失效，需要重新登录"},
+
+		// 第三方登录
+		{Method: http.MethodGet, Path: "/api/v1/oauth/providers", Tag: "第三方登录", Summary: "获取可用的第三方登录方式",
+			Data: []services.OIDCProvider{}},
+		{Method: http.MethodGet, Path: "/api/v1/oauth/:provider/authorize", Tag: "第三方登录", Summary: "发起第三方登录",
+			Data: controllers.AuthURLResponse{}, Errors: []int{http.StatusBadGateway},
+			Description: "返回身份提供方的授权页面地址（授权码模式 + PKCE），前端跳转后由身份提供方带 code 和 state 跳回配置的 redirect_url。state 在 oidc.state_ttl 内有效，只能使用一次"},
+		{Method: http.MethodPost, Path: "/api/v1/oauth/:provider/callback", Tag: "第三方登录", Summary: "完成第三方登录",
+			Body: controllers.OAuthCallbackRequest{}, Data: controllers.LoginResponse{},
+			Errors: []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusConflict, http.StatusBadGateway},
+			Description: "ID Token 通过身份提供方的签名公钥验证后签发本站令牌，响应与登录接口相同（已启用两步验证时返回 mfa_token）。" +
+				"身份未关联时，提供方配置了 allow_signup 则自动注册，否则返回 identity_not_linked；" +
+				"提供方返回的已验证邮箱已被本站账号使用时返回 identity_email_taken，需使用密码登录后在个人资料中关联"},
+		{Method: http.MethodGet, Path: "/api/v1/user/identities", Tag: "第三方登录", Summary: "获取已关联的第三方账号", Auth: apidoc.AuthUser,
+			Data: []models.UserIdentity{}},
+		{Method: http.MethodPost, Path: "/api/v1/user/identities/:provider/authorize", Tag: "第三方登录", Summary: "发起关联第三方账号", Auth: apidoc.AuthUser,
+			Data: controllers.AuthURLResponse{}, Errors: []int{http.StatusBadGateway},
+			Description: "与发起第三方登录相同，但 state 绑定当前用户，回调参数需提交到 POST /user/identities/{provider}"},
+		{Method: http.MethodPost, Path: "/api/v1/user/identities/:provider", Tag: "第三方登录", Summary: "关联第三方账号", Auth: apidoc.AuthUser,
+			Body: controllers.OAuthCallbackRequest{}, Data: models.UserIdentity{},
+			Errors:      []int{http.StatusConflict, http.StatusBadGateway},
+			Description: "该第三方账号已关联其他用户时返回 identity_taken，当前用户已关联该提供方时返回 identity_provider_linked"},
+		{Method: http.MethodDelete, Path: "/api/v1/user/identities/:provider", Tag: "第三方登录", Summary: "解除关联第三方账号", Auth: apidoc.AuthUser,
+			Description: "通过第三方登录注册的账号没有密码，需先通过找回密码设置密码才能解除最后一个关联"},
+
+		// 文章
+		{Method: http.MethodGet, Path: "/api/v1/articles", Tag: "文章", Summary: "获取文章列表",
+			Description: "游标分页：首次请求不传 cursor，之后传入上次返回的 next_cursor 或 prev_cursor。" +
+				"传入 page 且不传 cursor 时按页码分页，返回 total、page、size（已废弃）。" +
+				"tags 为逗号分隔的标签ID，tag_mode=all 时需包含全部标签；时间范围支持 RFC3339 或 2006-01-02，结束日期当天包含在内。" +
+				"sort 可选 created_at、updated_at、views、likes、comments，\"-\" 前缀表示倒序，不传时置顶优先、按创建时间倒序。" +
+				"fields 为逗号分隔的返回字段，如 id,title,author。",
+			Query: controllers.ArticleListQuery{}, Data: views.ArticleListItem{}, Cursor: true},
+		{Method: http.MethodGet, Path: "/api/v1/articles/:id", Tag: "文章", Summary: "获取文章详情",
+			Data: views.ArticleDetail{}, Description: "可选携带令牌，作者本人和管理员访问时返回 ArticleOwnerView，额外包含 status。"},
+		{Method: http.MethodPost, Path: "/api/v1/articles", Tag: "文章", Summary: "创建文章", Auth: apidoc.AuthUser,
+			Body: controllers.CreateArticleRequest{}, Data: controllers.CreatedResponse{}, Errors: []int{http.StatusForbidden}},
+		{Method: http.MethodPut, Path: "/api/v1/articles/:id", Tag: "文章", Summary: "更新文章", Auth: apidoc.AuthUser,
+			Body: controllers.UpdateArticleRequest{}, Errors: []int{http.StatusForbidden}},
+		{Method: http.MethodDelete, Path: "/api/v1/articles/:id", Tag: "文章", Summary: "删除文章", Auth: apidoc.AuthUser},
+		{Method: http.MethodPost, Path: "/api/v1/articles/:id/like", Tag: "文章", Summary: "点赞文章", Auth: apidoc.AuthUser,
+			Errors: []int{http.StatusForbidden}},
+
+		// 分类
+		{Method: http.MethodGet, Path: "/api/v1/categories", Tag: "分类", Summary: "获取分类列表",
+			Data: []models.Category{}},
+		{Method: http.MethodGet, Path: "/api/v1/categories/:id", Tag: "分类", Summary: "获取分类详情",
+			Data: models.Category{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/categories", Tag: "分类", Summary: "创建分类", Auth: apidoc.AuthAdmin,
+			Body: controllers.CreateCategoryRequest{}, Data: models.Category{}, Errors: []int{http.StatusConflict}},
+		{Method: http.MethodPut, Path: "/api/v1/admin/categories/:id", Tag: "分类", Summary: "更新分类", Auth: apidoc.AuthAdmin,
+			Body: controllers.UpdateCategoryRequest{}, Errors: []int{http.StatusConflict}},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/categories/:id", Tag: "分类", Summary: "删除分类", Auth: apidoc.AuthAdmin,
+			Description: "分类下还有文章时返回 409", Errors: []int{http.StatusConflict}},
+
+		// 文件上传
+		{Method: http.MethodPost, Path: "/api/v1/upload", Tag: "文件", Summary: "上传文件", Auth: apidoc.AuthUser,
+			Upload: "file", Data: controllers.UploadResponse{}, Errors: []int{http.StatusForbidden, http.StatusRequestEntityTooLarge}},
+
+		// 用户管理
+		{Method: http.MethodGet, Path: "/api/v1/admin/users", Tag: "用户管理", Summary: "搜索用户", Auth: apidoc.AuthAdmin,
+			Query: controllers.UserSearchQuery{}, Data: models.User{}, Page: true, Description: "username、email 为模糊匹配，role、status 为精确匹配"},
+		{Method: http.MethodGet, Path: "/api/v1/admin/users/:id", Tag: "用户管理", Summary: "获取用户信息", Auth: apidoc.AuthAdmin,
+			Data: models.User{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:id/ban", Tag: "用户管理", Summary: "封禁用户", Auth: apidoc.AuthAdmin,
+			Body:        controllers.BanUserRequest{},
+			Description: "until 为空表示永久封禁，到期后用户下次登录时自动解封。封禁在所有实例上立即生效（每次请求从主库读取用户状态），已签发的令牌无法继续访问。不能封禁自己。"},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:id/unban", Tag: "用户管理", Summary: "解除封禁", Auth: apidoc.AuthAdmin},
+		{Method: http.MethodPut, Path: "/api/v1/admin/users/:id/role", Tag: "用户管理", Summary: "修改用户角色", Auth: apidoc.AuthAdmin,
+			Description: "不能修改自己的角色", Body: controllers.ChangeRoleRequest{}},
+		{Method: http.MethodPost, Path: "/api/v1/admin/users/:id/logout", Tag: "用户管理", Summary: "强制用户下线", Auth: apidoc.AuthAdmin,
+			Description: "使该用户已签发的访问令牌和刷新令牌全部失效"},
+		{Method: http.MethodGet, Path: "/api/v1/admin/users/:id/articles", Tag: "用户管理", Summary: "获取用户的文章", Auth: apidoc.AuthAdmin,
+			Description: "包含草稿", Query: controllers.PageQuery{}, Data: views.ArticleOwnerItem{}, Page: true},
+		{Method: http.MethodGet, Path: "/api/v1/admin/users/:id/comments", Tag: "用户管理", Summary: "获取用户的评论", Auth: apidoc.AuthAdmin,
+			Query: controllers.PageQuery{}, Data: views.CommentItem{}, Page: true},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/users/:id/2fa", Tag: "用户管理", Summary: "重置两步验证", Auth: apidoc.AuthAdmin,
+			Description: "清除用户的两步验证密钥和恢复码，用于用户丢失验证设备的情况。不能重置自己"},
+		{Method: http.MethodGet, Path: "/api/v1/admin/login-locks", Tag: "用户管理", Summary: "获取登录锁定列表", Auth: apidoc.AuthAdmin,
+			Data: []repository.LoginLock{}, Description: "因登录失败次数过多被锁定、尚未到期的用户名，按到期时间升序"},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/login-locks/:username", Tag: "用户管理", Summary: "解除登录锁定", Auth: apidoc.AuthAdmin,
+			Errors: []int{http.StatusNotFound}, Description: "同时清零该用户名的失败次数"},
+
+		// 不出现在文档中的路由
+		{Method: http.MethodGet, Path: "/uploads/*filepath", Hidden: true},
+		{Method: http.MethodHead, Path: "/uploads/*filepath", Hidden: true},
+		{Method: http.MethodGet, Path: metricsPath(), Hidden: true},
+		{Method: http.MethodGet, Path: openAPIPath, Hidden: true},
+		{Method: http.MethodGet, Path: docsPath + "/*filepath", Hidden: true},
+	}
+}
+
+// OpenAPI 根据已注册的路由生成接口文档，problems 列出缺少文档的路由和多余的文档
+func OpenAPI(r *gin.Engine) (*apidoc.Document, []string) {
+	info := apidoc.Info{Title: "Blog Backend API", Version: "dev"}
+	if cfg := config.Get(); cfg != nil {
+		info.Title = cfg.App.Name + " API"
+		info.Version = cfg.App.Version
+	}
+	return apidoc.Build(info, r.Routes(), apiDocs())
+}
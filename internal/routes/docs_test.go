@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/xiaoxin/blog-backend/internal/container"
+	"github.com/xiaoxin/blog-backend/internal/repository/memory"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+)
+
+// newTestRouter 基于内存仓储注册全部路由
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+	prev := config.Get()
+	config.Set(&config.Config{
+		App:     config.AppConfig{Name: "blog-test", Version: "test"},
+		Metrics: config.MetricsConfig{Enabled: true},
+	})
+	t.Cleanup(func() { config.Set(prev) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	SetupRoutes(r, container.New(memory.NewRepositories()))
+	return r
+}
+
+func TestOpenAPIComplete(t *testing.T) {
+	r := newTestRouter(t)
+
+	doc, problems := OpenAPI(r)
+	for _, p := range problems {
+		t.Error(p)
+	}
+	if doc == nil || len(doc.Paths) == 0 {
+		t.Fatal("OpenAPI() returned no paths")
+	}
+	if doc.Info.Title != "blog-test API" {
+		t.Errorf("title = %q", doc.Info.Title)
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	r := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, openAPIPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d", openAPIPath, w.Code)
+	}
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI == "" || doc.Paths[openAPIPath] != nil || doc.Paths["/api/v1/articles"] == nil {
+		t.Errorf("served document: openapi %q, %d paths", doc.OpenAPI, len(doc.Paths))
+	}
+}
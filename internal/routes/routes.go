@@ -1,19 +1,33 @@
 package routes
 
 import (
+	"sync"
+
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 
-	"github.com/xiaoxin/blog-backend/internal/controllers"
+	"github.com/xiaoxin/blog-backend/internal/apidoc"
+	"github.com/xiaoxin/blog-backend/internal/container"
 	"github.com/xiaoxin/blog-backend/internal/middleware"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
 )
 
 // SetupRoutes 设置路由
-func SetupRoutes(r *gin.Engine) {
-	// 初始化控制器
-	userCtrl := controllers.NewUserController()
-	articleCtrl := controllers.NewArticleController()
-	categoryCtrl := controllers.NewCategoryController()
-	uploadCtrl := controllers.NewUploadController()
+func SetupRoutes(r *gin.Engine, c *container.Container) {
+	// 控制器
+	userCtrl := c.UserController
+	articleCtrl := c.ArticleController
+	categoryCtrl := c.CategoryController
+	uploadCtrl := c.UploadController
+	adminUserCtrl := c.AdminUserController
+	oidcCtrl := c.OIDCController
+
+	// 邮箱未验证的用户按 email_verification.restrict 限制的操作
+	verified := func(action string) gin.HandlerFunc {
+		return middleware.RequireVerifiedEmail(c.UserService, action)
+	}
 
 	// 公开路由
 	api := r.Group("/api/v1")
@@ -21,10 +35,19 @@ func SetupRoutes(r *gin.Engine) {
 	// 用户相关
 	api.POST("/register", userCtrl.Register)
 	api.POST("/login", userCtrl.Login)
+	api.POST("/login/2fa", userCtrl.LoginTwoFactor)
+	api.GET("/email/verify", userCtrl.VerifyEmail)
+	api.POST("/password/forgot", userCtrl.ForgotPassword)
+	api.POST("/password/reset", userCtrl.ResetPassword)
+
+	// 第三方登录
+	api.GET("/oauth/providers", oidcCtrl.ListProviders)
+	api.GET("/oauth/:provider/authorize", oidcCtrl.Authorize)
+	api.POST("/oauth/:provider/callback", oidcCtrl.Callback)
 
 	// 文章相关（公开访问）
 	api.GET("/articles", articleCtrl.GetArticleList)
-	api.GET("/articles/:id", articleCtrl.GetArticle)
+	api.GET("/articles/:id", middleware.OptionalAuth(c.UserService), articleCtrl.GetArticle)
 
 	// 分类相关（公开访问）
 	api.GET("/categories", categoryCtrl.GetCategoryList)
@@ -32,29 +55,49 @@ func SetupRoutes(r *gin.Engine) {
 
 	// 需要认证的路由
 	auth := r.Group("/api/v1")
-	auth.Use(middleware.JWTAuth())
+	auth.Use(middleware.JWTAuth(c.UserService))
 	{
 		// 用户相关
 		auth.GET("/user/profile", userCtrl.GetProfile)
 		auth.PUT("/user/profile", userCtrl.UpdateProfile)
 		auth.PUT("/user/password", userCtrl.ChangePassword)
+		auth.POST("/user/email/verification", userCtrl.ResendVerification)
+		auth.GET("/user/2fa", userCtrl.GetTwoFactorStatus)
+		auth.POST("/user/2fa/setup", userCtrl.SetupTwoFactor)
+		auth.POST("/user/2fa/enable", userCtrl.EnableTwoFactor)
+		auth.POST("/user/2fa/disable", userCtrl.DisableTwoFactor)
+		auth.POST("/user/2fa/recovery-codes", userCtrl.RegenerateRecoveryCodes)
+		auth.GET("/user/identities", oidcCtrl.ListIdentities)
+		auth.POST("/user/identities/:provider/authorize", oidcCtrl.AuthorizeLink)
+		auth.POST("/user/identities/:provider", oidcCtrl.LinkIdentity)
+		auth.DELETE("/user/identities/:provider", oidcCtrl.UnlinkIdentity)
 
 		// 文件上传
-		auth.POST("/upload", uploadCtrl.UploadFile)
+		auth.POST("/upload", verified(config.ActionUpload), uploadCtrl.UploadFile)
 
 		// 文章相关（需要认证）
-		auth.POST("/articles", articleCtrl.CreateArticle)
-		auth.PUT("/articles/:id", articleCtrl.UpdateArticle)
+		auth.POST("/articles", verified(config.ActionArticle), articleCtrl.CreateArticle)
+		auth.PUT("/articles/:id", verified(config.ActionArticle), articleCtrl.UpdateArticle)
 		auth.DELETE("/articles/:id", articleCtrl.DeleteArticle)
-		auth.POST("/articles/:id/like", articleCtrl.LikeArticle)
+		auth.POST("/articles/:id/like", verified(config.ActionLike), articleCtrl.LikeArticle)
 	}
 
 	// 管理员路由
 	admin := r.Group("/api/v1/admin")
-	admin.Use(middleware.JWTAuth(), middleware.RequireRole("admin"))
+	admin.Use(middleware.JWTAuth(c.UserService), middleware.RequireRole("admin"), middleware.RequireAdminTwoFactor(c.UserService))
 	{
 		// 用户管理
+		admin.GET("/users", adminUserCtrl.SearchUsers)
 		admin.GET("/users/:id", userCtrl.GetUserByID)
+		admin.POST("/users/:id/ban", adminUserCtrl.BanUser)
+		admin.POST("/users/:id/unban", adminUserCtrl.UnbanUser)
+		admin.PUT("/users/:id/role", adminUserCtrl.ChangeRole)
+		admin.POST("/users/:id/logout", adminUserCtrl.ForceLogout)
+		admin.GET("/users/:id/articles", adminUserCtrl.ListUserArticles)
+		admin.GET("/users/:id/comments", adminUserCtrl.ListUserComments)
+		admin.DELETE("/users/:id/2fa", adminUserCtrl.ResetTwoFactor)
+		admin.GET("/login-locks", adminUserCtrl.ListLoginLocks)
+		admin.DELETE("/login-locks/:username", adminUserCtrl.UnlockLogin)
 
 		// 分类管理
 		admin.POST("/categories", categoryCtrl.CreateCategory)
@@ -62,6 +105,44 @@ func SetupRoutes(r *gin.Engine) {
 		admin.DELETE("/categories/:id", categoryCtrl.DeleteCategory)
 	}
 
-	// 静态文件服务（上传的文件）
-	r.Static("/uploads", "./uploads")
+	// 静态文件服务（上传的文件），目录在启动时确定，修改 upload.save_path 需重启
+	r.Static("/uploads", uploadDir())
+
+	// 监控指标
+	if cfg := config.Get(); cfg != nil && cfg.Metrics.Enabled {
+		r.GET(metricsPath(), metrics.Handler())
+	}
+
+	// 接口文档，需在所有路由注册完成后生成
+	var (
+		once sync.Once
+		spec gin.HandlerFunc
+	)
+	r.GET(openAPIPath, func(ctx *gin.Context) {
+		once.Do(func() {
+			doc, problems := OpenAPI(r)
+			if len(problems) > 0 {
+				logger.Warn("接口文档不完整", zap.Strings("problems", problems))
+			}
+			spec = apidoc.SpecHandler(doc)
+		})
+		spec(ctx)
+	})
+	r.GET(docsPath+"/*filepath", apidoc.UIHandler(openAPIPath))
+}
+
+// uploadDir 上传文件的保存目录
+func uploadDir() string {
+	if cfg := config.Get(); cfg != nil && cfg.Upload.SavePath != "" {
+		return cfg.Upload.SavePath
+	}
+	return "./uploads"
+}
+
+// metricsPath 监控指标的抓取路径
+func metricsPath() string {
+	if cfg := config.Get(); cfg != nil && cfg.Metrics.Path != "" {
+		return cfg.Metrics.Path
+	}
+	return "/metrics"
 }
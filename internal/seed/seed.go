@@ -0,0 +1,249 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+)
+
+// DefaultPassword 生成用户的默认密码
+const DefaultPassword = "password123"
+
+// batchSize 批量插入的大小
+const batchSize = 200
+
+// baseTime 生成数据的起始时间，固定值保证相同种子生成相同数据
+var baseTime = time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+// Options 生成参数
+type Options struct {
+	Seed       int64
+	Users      int
+	Categories int
+	Tags       int
+	Articles   int
+	Comments   int
+}
+
+// Result 生成结果统计
+type Result struct {
+	Users      int
+	Categories int
+	Tags       int
+	Articles   int
+	Comments   int
+}
+
+// Run 按参数生成开发数据，相同的 Seed 生成相同的数据
+// 第一个用户为管理员，所有用户的密码均为 DefaultPassword
+func Run(ctx context.Context, db *gorm.DB, opts Options) (*Result, error) {
+	if opts.Users <= 0 {
+		return nil, fmt.Errorf("用户数量必须大于0")
+	}
+	if opts.Articles > 0 && opts.Categories <= 0 {
+		return nil, fmt.Errorf("生成文章时分类数量必须大于0")
+	}
+
+	r := rand.New(rand.NewSource(opts.Seed))
+	db = db.WithContext(ctx)
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(DefaultPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		users := genUsers(r, opts.Users, string(hashed))
+		if err := tx.CreateInBatches(users, batchSize).Error; err != nil {
+			return fmt.Errorf("生成用户失败: %w", err)
+		}
+		result.Users = len(users)
+
+		categories := genCategories(opts.Categories)
+		if len(categories) > 0 {
+			if err := tx.CreateInBatches(categories, batchSize).Error; err != nil {
+				return fmt.Errorf("生成分类失败: %w", err)
+			}
+		}
+		result.Categories = len(categories)
+
+		tags := genTags(opts.Tags)
+		if len(tags) > 0 {
+			if err := tx.CreateInBatches(tags, batchSize).Error; err != nil {
+				return fmt.Errorf("生成标签失败: %w", err)
+			}
+		}
+		result.Tags = len(tags)
+
+		articles := genArticles(r, opts.Articles, users, categories, tags)
+		if len(articles) > 0 {
+			// 标签已存在，只写入 article_tags 关联
+			if err := tx.Omit("Author", "Category", "Comments", "Tags.*").CreateInBatches(articles, batchSize).Error; err != nil {
+				return fmt.Errorf("生成文章失败: %w", err)
+			}
+		}
+		result.Articles = len(articles)
+
+		n, err := genComments(tx, r, opts.Comments, users, articles)
+		if err != nil {
+			return fmt.Errorf("生成评论失败: %w", err)
+		}
+		result.Comments = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func genUsers(r *rand.Rand, n int, hashedPassword string) []*models.User {
+	users := make([]*models.User, n)
+	for i := range users {
+		name := fmt.Sprintf("%s_%03d", firstNames[i%len(firstNames)], i+1)
+		role := models.RoleUser
+		if i == 0 {
+			name = "admin"
+			role = models.RoleAdmin
+		}
+
+		createdAt := baseTime.Add(time.Duration(r.Intn(30*24)) * time.Hour)
+		users[i] = &models.User{
+			BaseModel:       models.BaseModel{CreatedAt: createdAt, UpdatedAt: createdAt},
+			Username:        name,
+			Password:        hashedPassword,
+			Email:           name + "@example.com",
+			EmailVerifiedAt: &createdAt,
+			Nickname:        pick(r, nicknames),
+			Role:            role,
+			Status:          models.UserStatusActive,
+		}
+	}
+	return users
+}
+
+func genCategories(n int) []*models.Category {
+	categories := make([]*models.Category, n)
+	for i := range categories {
+		categories[i] = &models.Category{
+			BaseModel:   models.BaseModel{CreatedAt: baseTime, UpdatedAt: baseTime},
+			Name:        uniqueName(categoryNames, i),
+			Description: categoryDescs[i%len(categoryDescs)],
+			Sort:        i,
+		}
+	}
+	return categories
+}
+
+func genTags(n int) []*models.Tag {
+	tags := make([]*models.Tag, n)
+	for i := range tags {
+		tags[i] = &models.Tag{
+			BaseModel: models.BaseModel{CreatedAt: baseTime, UpdatedAt: baseTime},
+			Name:      uniqueName(tagNames, i),
+		}
+	}
+	return tags
+}
+
+func genArticles(r *rand.Rand, n int, users []*models.User, categories []*models.Category, tags []*models.Tag) []*models.Article {
+	articles := make([]*models.Article, n)
+	for i := range articles {
+		author := users[r.Intn(len(users))]
+		category := categories[r.Intn(len(categories))]
+		title, description, content := articleText(r)
+
+		// 每篇文章 0~4 个不重复的标签
+		var articleTags []models.Tag
+		if len(tags) > 0 {
+			count := r.Intn(min(len(tags), 4) + 1)
+			for _, idx := range r.Perm(len(tags))[:count] {
+				articleTags = append(articleTags, *tags[idx])
+			}
+		}
+
+		createdAt := author.CreatedAt.Add(time.Duration(r.Intn(300*24*60)) * time.Minute)
+		updatedAt := createdAt
+		if r.Intn(3) == 0 {
+			updatedAt = createdAt.Add(time.Duration(r.Intn(30*24)) * time.Hour)
+		}
+
+		status := 1
+		if r.Intn(10) == 0 {
+			status = 0
+		}
+
+		articles[i] = &models.Article{
+			BaseModel:   models.BaseModel{CreatedAt: createdAt, UpdatedAt: updatedAt},
+			Title:       title,
+			Description: description,
+			Content:     content,
+			AuthorID:    author.ID,
+			CategoryID:  category.ID,
+			Tags:        articleTags,
+			ViewCount:   r.Intn(5000),
+			LikeCount:   r.Intn(300),
+			Status:      status,
+			IsTop:       r.Intn(50) == 0,
+		}
+	}
+	return articles
+}
+
+// genComments 生成评论，约三成为对同一文章已有评论的回复
+func genComments(tx *gorm.DB, r *rand.Rand, n int, users []*models.User, articles []*models.Article) (int, error) {
+	if n <= 0 || len(articles) == 0 {
+		return 0, nil
+	}
+
+	topLevel := n - n*3/10
+	comments := make([]*models.Comment, 0, topLevel)
+	for i := 0; i < topLevel; i++ {
+		article := articles[r.Intn(len(articles))]
+		comments = append(comments, newComment(r, users, article.ID, article.CreatedAt, nil))
+	}
+	if err := tx.Omit("Article", "User").CreateInBatches(comments, batchSize).Error; err != nil {
+		return 0, err
+	}
+
+	replies := make([]*models.Comment, 0, n-topLevel)
+	for i := topLevel; i < n; i++ {
+		parent := comments[r.Intn(len(comments))]
+		parentID := parent.ID
+		replies = append(replies, newComment(r, users, parent.ArticleID, parent.CreatedAt, &parentID))
+	}
+	if len(replies) > 0 {
+		if err := tx.Omit("Article", "User").CreateInBatches(replies, batchSize).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	// 回填文章的评论数
+	err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Model(&models.Article{}).
+		UpdateColumn("comment_count", gorm.Expr("(SELECT COUNT(*) FROM comments WHERE comments.article_id = articles.id AND comments.deleted_at IS NULL)")).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return len(comments) + len(replies), nil
+}
+
+func newComment(r *rand.Rand, users []*models.User, articleID uint, after time.Time, parentID *uint) *models.Comment {
+	createdAt := after.Add(time.Duration(r.Intn(14*24*60)+1) * time.Minute)
+	return &models.Comment{
+		BaseModel: models.BaseModel{CreatedAt: createdAt, UpdatedAt: createdAt},
+		ArticleID: articleID,
+		UserID:    users[r.Intn(len(users))].ID,
+		Content:   commentText(r),
+		ParentID:  parentID,
+		Status:    1,
+	}
+}
@@ -1,121 +1,378 @@
 package services
 
 import (
+	"context"
 	"errors"
-
-	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/xiaoxin/blog-backend/internal/models"
-	"github.com/xiaoxin/blog-backend/pkg/database"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/cursor"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
+	"github.com/xiaoxin/blog-backend/pkg/query"
 )
 
 // ArticleService 文章服务
-type ArticleService struct{}
+type ArticleService struct {
+	articles repository.ArticleRepository
+	uow      repository.UnitOfWork
+}
 
 // NewArticleService 创建文章服务实例
-func NewArticleService() *ArticleService {
-	return &ArticleService{}
+func NewArticleService(articles repository.ArticleRepository, uow repository.UnitOfWork) *ArticleService {
+	return &ArticleService{
+		articles: articles,
+		uow:      uow,
+	}
 }
 
 // CreateArticle 创建文章
-func (s *ArticleService) CreateArticle(article *models.Article) error {
-	db := database.GetDB()
-	return db.Create(article).Error
+func (s *ArticleService) CreateArticle(ctx context.Context, article *models.Article) error {
+	return s.uow.Do(ctx, func(ctx context.Context, tx *repository.Repositories) error {
+		// 校验分类和标签
+		tags, err := checkArticleRefs(ctx, tx, article.CategoryID, article.Tags)
+		if err != nil {
+			return err
+		}
+		article.Tags = tags
+
+		if err := tx.Articles.Create(ctx, article); err != nil {
+			return err
+		}
+
+		if article.Status == 1 {
+			repository.AfterCommit(ctx, metrics.ArticlesPublished.Inc)
+		}
+		return nil
+	})
 }
 
 // GetArticleByID 根据ID获取文章
-func (s *ArticleService) GetArticleByID(id uint) (*models.Article, error) {
-	db := database.GetDB()
-
-	var article models.Article
-	if err := db.Preload("Author").Preload("Category").Preload("Tags").First(&article, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("文章不存在")
+func (s *ArticleService) GetArticleByID(ctx context.Context, id uint) (*models.Article, error) {
+	article, err := s.articles.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrArticleNotFound
 		}
 		return nil, err
 	}
 
-	return &article, nil
+	return article, nil
 }
 
-// GetArticleList 获取文章列表
-func (s *ArticleService) GetArticleList(page, pageSize int, status *int, categoryID *uint) ([]models.Article, int64, error) {
-	db := database.GetDB()
+// 文章列表分页大小
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
 
-	var articles []models.Article
-	var total int64
+// ArticleSorts 文章列表 sort 参数允许的字段，值为仓储的排序字段
+var ArticleSorts = query.Whitelist{
+	"created_at": repository.ArticleSortCreatedAt,
+	"updated_at": repository.ArticleSortUpdatedAt,
+	"views":      repository.ArticleSortViews,
+	"likes":      repository.ArticleSortLikes,
+	"comments":   repository.ArticleSortComments,
+}
 
-	query := db.Model(&models.Article{})
+// ArticleFields 文章列表项的字段，即 fields 参数允许的值，值为需要查询的数据库列，关联字段为其外键
+// 列表不包含正文，未选择字段时查询这里的全部列
+var ArticleFields = query.Whitelist{
+	"id":            "id",
+	"title":         "title",
+	"description":   "description",
+	"cover":         "cover",
+	"author_id":     "author_id",
+	"author":        "author_id",
+	"category_id":   "category_id",
+	"category":      "category_id",
+	"tags":          "id",
+	"view_count":    "view_count",
+	"like_count":    "like_count",
+	"comment_count": "comment_count",
+	"is_top":        "is_top",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+}
 
-	// 筛选条件
-	if status != nil {
-		query = query.Where("status = ?", *status)
-	}
-	if categoryID != nil {
-		query = query.Where("category_id = ?", *categoryID)
+// ArticleListQuery 文章列表查询条件
+type ArticleListQuery struct {
+	repository.ArticleFilter
+
+	Sort       query.Order // 排序字段为 ArticleSorts 中的值，零值为默认排序
+	Fields     []string    // 需要返回的字段，为 ArticleFields 中的名称，为空时返回列表项的全部字段
+	WithStatus bool        // 同时查询发布状态，用于作者本人和管理员的列表
+
+	Cursor    string // 上一次返回的 next_cursor 或 prev_cursor，为空时从第一页开始
+	PageSize  int
+	WithTotal bool // 是否统计总数，需要额外执行一次 COUNT 查询
+}
+
+// sort 仓储的排序方式
+func (q *ArticleListQuery) sort() repository.ArticleSort {
+	return repository.ArticleSort{Field: q.Sort.Field, Desc: q.Sort.Desc}
+}
+
+// selection 根据 Fields 确定需要查询的列和关联，排序列总是查询，用于生成游标
+func (q *ArticleListQuery) selection() *repository.ArticleSelect {
+	fields := q.Fields
+	if len(fields) == 0 {
+		fields = ArticleFields.Names()
 	}
 
-	// 统计总数
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	sel := &repository.ArticleSelect{}
+	seen := make(map[string]bool)
+	add := func(column string) {
+		if !seen[column] {
+			seen[column] = true
+			sel.Columns = append(sel.Columns, column)
+		}
 	}
 
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := query.Preload("Author").Preload("Category").Preload("Tags").
-		Order("is_top DESC, created_at DESC").
-		Limit(pageSize).Offset(offset).
-		Find(&articles).Error; err != nil {
-		return nil, 0, err
+	for _, column := range q.sort().Columns() {
+		add(column.Name)
+	}
+	if q.WithStatus {
+		add("status")
+	}
+	for _, field := range fields {
+		add(ArticleFields[field])
+		switch field {
+		case "author":
+			sel.Author = true
+		case "category":
+			sel.Category = true
+		case "tags":
+			sel.Tags = true
+		}
 	}
+	return sel
+}
 
-	return articles, total, nil
+// ArticleListResult 文章列表游标分页结果
+type ArticleListResult struct {
+	Articles   []models.Article
+	NextCursor string // 下一页游标，没有下一页时为空
+	PrevCursor string // 上一页游标，没有上一页时为空
+	Total      *int64 // 总数，未要求统计时为 nil
 }
 
-// UpdateArticle 更新文章
-func (s *ArticleService) UpdateArticle(id uint, article *models.Article) error {
-	db := database.GetDB()
+// articleCursor 文章列表游标的内容，只保存当前排序用到的列
+type articleCursor struct {
+	Before bool   `json:"b,omitempty"` // 是否向前翻页
+	Sort   string `json:"s,omitempty"` // 生成游标时的排序，与本次请求不一致时游标无效
 
-	// 检查文章是否存在
-	var existingArticle models.Article
-	if err := db.First(&existingArticle, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("文章不存在")
+	IsTop        bool       `json:"t,omitempty"`
+	CreatedAt    *time.Time `json:"c,omitempty"`
+	UpdatedAt    *time.Time `json:"u,omitempty"`
+	ViewCount    int        `json:"v,omitempty"`
+	LikeCount    int        `json:"l,omitempty"`
+	CommentCount int        `json:"m,omitempty"`
+	ID           uint       `json:"i"`
+}
+
+// ListArticles 按游标分页获取文章列表
+// 按排序键定位，翻页时不受新发布文章的影响，深翻页也无需扫描前面的记录
+func (s *ArticleService) ListArticles(ctx context.Context, q ArticleListQuery) (*ArticleListResult, error) {
+	size := clampPageSize(q.PageSize)
+	sort := q.sort()
+
+	// 多查一条用于判断该方向上是否还有数据
+	page := repository.ArticlePage{Limit: size + 1, Sort: sort, Select: q.selection()}
+	var cur articleCursor
+	if q.Cursor != "" {
+		if err := cursor.Decode(q.Cursor, &cur); err != nil || cur.Sort != sortKey(sort) {
+			return nil, ErrInvalidCursor
+		}
+		key := cur.key()
+		if cur.Before {
+			page.Before = &key
+		} else {
+			page.After = &key
 		}
-		return err
 	}
 
-	// 更新文章
-	article.ID = id
-	if err := db.Model(&models.Article{}).Where("id = ?", id).Updates(article).Error; err != nil {
-		return err
+	articles, err := s.articles.List(ctx, q.ArticleFilter, page)
+	if err != nil {
+		return nil, err
 	}
 
-	// 更新标签关联
-	if len(article.Tags) > 0 {
-		if err := db.Model(&existingArticle).Association("Tags").Replace(article.Tags); err != nil {
-			return err
+	more := len(articles) > size
+	if more {
+		if cur.Before {
+			articles = articles[1:]
+		} else {
+			articles = articles[:size]
 		}
 	}
 
-	return nil
+	// 向前翻页时后面一定还有数据，向后翻页时前面一定还有数据
+	hasNext, hasPrev := more, q.Cursor != ""
+	if cur.Before {
+		hasNext, hasPrev = true, more
+	}
+
+	result := &ArticleListResult{Articles: articles}
+	if len(articles) > 0 {
+		if hasNext {
+			if result.NextCursor, err = encodeArticleCursor(sort, &articles[len(articles)-1], false); err != nil {
+				return nil, err
+			}
+		}
+		if hasPrev {
+			if result.PrevCursor, err = encodeArticleCursor(sort, &articles[0], true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if q.WithTotal {
+		total, err := s.articles.Count(ctx, q.ArticleFilter)
+		if err != nil {
+			return nil, err
+		}
+		result.Total = &total
+	}
+	return result, nil
 }
 
-// DeleteArticle 删除文章
-func (s *ArticleService) DeleteArticle(id uint) error {
-	db := database.GetDB()
+// GetArticleList 按页码获取文章列表及总数，忽略 q 中的游标
+// Deprecated: 深翻页性能差且新文章会导致翻页重复，请使用 ListArticles
+func (s *ArticleService) GetArticleList(ctx context.Context, q ArticleListQuery, page int) ([]models.Article, int64, error) {
+	pageSize := clampPageSize(q.PageSize)
+	if page < 1 {
+		page = 1
+	}
+
+	articles, err := s.articles.List(ctx, q.ArticleFilter, repository.ArticlePage{
+		Offset: (page - 1) * pageSize,
+		Limit:  pageSize,
+		Sort:   q.sort(),
+		Select: q.selection(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// 检查文章是否存在
-	var article models.Article
-	if err := db.First(&article, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("文章不存在")
+	total, err := s.articles.Count(ctx, q.ArticleFilter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return articles, total, nil
+}
+
+// encodeArticleCursor 生成指向文章的游标，before 表示从该文章向前翻页
+func encodeArticleCursor(sort repository.ArticleSort, article *models.Article, before bool) (string, error) {
+	cur := articleCursor{Before: before, Sort: sortKey(sort), ID: article.ID}
+	for _, column := range sort.Columns() {
+		switch column.Name {
+		case "is_top":
+			cur.IsTop = article.IsTop
+		case repository.ArticleSortCreatedAt:
+			cur.CreatedAt = &article.CreatedAt
+		case repository.ArticleSortUpdatedAt:
+			cur.UpdatedAt = &article.UpdatedAt
+		case repository.ArticleSortViews:
+			cur.ViewCount = article.ViewCount
+		case repository.ArticleSortLikes:
+			cur.LikeCount = article.LikeCount
+		case repository.ArticleSortComments:
+			cur.CommentCount = article.CommentCount
 		}
-		return err
 	}
+	return cursor.Encode(cur)
+}
+
+// key 游标指向的排序键
+func (c *articleCursor) key() repository.ArticleKey {
+	key := repository.ArticleKey{
+		IsTop:        c.IsTop,
+		ViewCount:    c.ViewCount,
+		LikeCount:    c.LikeCount,
+		CommentCount: c.CommentCount,
+		ID:           c.ID,
+	}
+	if c.CreatedAt != nil {
+		key.CreatedAt = *c.CreatedAt
+	}
+	if c.UpdatedAt != nil {
+		key.UpdatedAt = *c.UpdatedAt
+	}
+	return key
+}
+
+// sortKey 排序方式的标识，默认排序为空
+func sortKey(sort repository.ArticleSort) string {
+	if sort.Field == "" {
+		return ""
+	}
+	if sort.Desc {
+		return "-" + sort.Field
+	}
+	return sort.Field
+}
+
+// clampPageSize 将分页大小限制在 1~MaxPageSize 之间，未指定时使用默认值
+func clampPageSize(size int) int {
+	switch {
+	case size <= 0:
+		return DefaultPageSize
+	case size > MaxPageSize:
+		return MaxPageSize
+	}
+	return size
+}
+
+// UpdateArticle 更新文章
+func (s *ArticleService) UpdateArticle(ctx context.Context, id uint, article *models.Article) error {
+	return s.uow.Do(ctx, func(ctx context.Context, tx *repository.Repositories) error {
+		// 检查文章是否存在
+		existingArticle, err := tx.Articles.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrArticleNotFound
+			}
+			return err
+		}
+
+		// 校验分类和标签
+		tags, err := checkArticleRefs(ctx, tx, article.CategoryID, article.Tags)
+		if err != nil {
+			return err
+		}
+
+		// 更新文章
+		article.ID = id
+		if err := tx.Articles.Update(ctx, id, article); err != nil {
+			return err
+		}
 
-	// 删除文章
-	if err := db.Delete(&article).Error; err != nil {
+		// 更新标签关联
+		if len(tags) > 0 {
+			if err := tx.Articles.ReplaceTags(ctx, id, tags); err != nil {
+				return err
+			}
+		}
+
+		// 草稿转为发布时计数
+		if existingArticle.Status != 1 && article.Status == 1 {
+			repository.AfterCommit(ctx, metrics.ArticlesPublished.Inc)
+		}
+		return nil
+	})
+}
+
+// DeleteArticle 删除文章
+func (s *ArticleService) DeleteArticle(ctx context.Context, id uint) error {
+	if err := s.articles.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrArticleNotFound
+		}
 		return err
 	}
 
@@ -123,13 +380,70 @@ func (s *ArticleService) DeleteArticle(id uint) error {
 }
 
 // IncrementViewCount 增加浏览量
-func (s *ArticleService) IncrementViewCount(id uint) error {
-	db := database.GetDB()
-	return db.Model(&models.Article{}).Where("id = ?", id).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).Error
+func (s *ArticleService) IncrementViewCount(ctx context.Context, id uint) error {
+	return s.articles.IncrementViewCount(ctx, id)
 }
 
 // IncrementLikeCount 增加点赞数
-func (s *ArticleService) IncrementLikeCount(id uint) error {
-	db := database.GetDB()
-	return db.Model(&models.Article{}).Where("id = ?", id).UpdateColumn("like_count", gorm.Expr("like_count + ?", 1)).Error
+func (s *ArticleService) IncrementLikeCount(ctx context.Context, id uint) error {
+	return s.articles.IncrementLikeCount(ctx, id)
+}
+
+// checkArticleRefs 校验文章引用的分类和标签是否存在，返回完整的标签记录
+func checkArticleRefs(ctx context.Context, tx *repository.Repositories, categoryID uint, tags []models.Tag) ([]models.Tag, error) {
+	if categoryID != 0 {
+		if _, err := tx.Categories.FindByID(ctx, categoryID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, invalidRef(ctx, ErrInvalidCategory, "category_id", categoryID)
+			}
+			return nil, err
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(tags))
+	seen := make(map[uint]bool, len(tags))
+	for _, tag := range tags {
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			ids = append(ids, tag.ID)
+		}
+	}
+
+	found, err := tx.Tags.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(ids) {
+		exists := make(map[uint]bool, len(found))
+		for _, tag := range found {
+			exists[tag.ID] = true
+		}
+		var missing []uint
+		for _, id := range ids {
+			if !exists[id] {
+				missing = append(missing, id)
+			}
+		}
+		return nil, invalidRef(ctx, ErrInvalidTag, "tag_ids", missing...)
+	}
+
+	return found, nil
+}
+
+// invalidRef 引用的记录不存在，details 中给出请求字段和不存在的ID，提示信息按请求语言翻译
+func invalidRef(ctx context.Context, kind *apperr.Error, field string, ids ...uint) error {
+	params := make([]string, len(ids))
+	for i, id := range ids {
+		params[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return kind.WithDetails([]validation.FieldError{{
+		Field:   field,
+		Rule:    "exists",
+		Param:   strings.Join(params, " "),
+		Message: kind.Localize(i18n.FromContext(ctx)),
+	}})
 }
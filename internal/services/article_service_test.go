@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/repository/memory"
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	"github.com/xiaoxin/blog-backend/pkg/query"
+)
+
+// newArticleService 基于内存仓储创建文章服务
+func newArticleService(t *testing.T) (*ArticleService, *memory.Store, *repository.Repositories) {
+	t.Helper()
+	store := memory.NewStore()
+	repos := store.Repositories()
+	return NewArticleService(repos.Articles, repos.UnitOfWork), store, repos
+}
+
+// mustCategory 创建分类，失败时终止测试
+func mustCategory(t *testing.T, repos *repository.Repositories, name string) uint {
+	t.Helper()
+	category := &models.Category{Name: name}
+	if err := repos.Categories.Create(context.Background(), category); err != nil {
+		t.Fatal(err)
+	}
+	return category.ID
+}
+
+func TestCreateArticle(t *testing.T) {
+	s, store, repos := newArticleService(t)
+	ctx := context.Background()
+	categoryID := mustCategory(t, repos, "Go")
+	tag := store.AddTag("gin")
+
+	tests := []struct {
+		name     string
+		category uint
+		tags     []models.Tag
+		want     error
+	}{
+		{"unknown category", categoryID + 1, nil, ErrInvalidCategory},
+		{"unknown tag", categoryID, []models.Tag{{BaseModel: models.BaseModel{ID: tag.ID + 1}}}, ErrInvalidTag},
+		{"valid", categoryID, []models.Tag{{BaseModel: models.BaseModel{ID: tag.ID}}}, nil},
+		{"duplicate tags", categoryID, []models.Tag{{BaseModel: models.BaseModel{ID: tag.ID}}, {BaseModel: models.BaseModel{ID: tag.ID}}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := &models.Article{Title: tt.name, Content: "content", AuthorID: 1, CategoryID: tt.category, Tags: tt.tags, Status: 1}
+			err := s.CreateArticle(ctx, article)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("CreateArticle() error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := s.GetArticleByID(ctx, article.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tags) != 1 || got.Tags[0].Name != "gin" {
+				t.Errorf("article tags = %+v, want [gin]", got.Tags)
+			}
+		})
+	}
+
+	total, err := repos.Articles.Count(ctx, repository.ArticleFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Errorf("article count = %d, want 2", total)
+	}
+}
+
+func TestArticleRefErrorDetails(t *testing.T) {
+	s, store, repos := newArticleService(t)
+	ctx := i18n.WithLocale(context.Background(), i18n.LocaleEn)
+	categoryID := mustCategory(t, repos, "Go")
+	tag := store.AddTag("gin")
+
+	tests := []struct {
+		name    string
+		article models.Article
+		want    validation.FieldError
+	}{
+		{
+			name:    "unknown category",
+			article: models.Article{CategoryID: categoryID + 1},
+			want:    validation.FieldError{Field: "category_id", Rule: "exists", Param: strconv.Itoa(int(categoryID + 1)), Message: "Category does not exist"},
+		},
+		{
+			name: "unknown tags",
+			article: models.Article{CategoryID: categoryID, Tags: []models.Tag{
+				{BaseModel: models.BaseModel{ID: tag.ID + 2}}, {BaseModel: models.BaseModel{ID: tag.ID}}, {BaseModel: models.BaseModel{ID: tag.ID + 1}},
+			}},
+			want: validation.FieldError{Field: "tag_ids", Rule: "exists", Param: fmt.Sprintf("%d %d", tag.ID+2, tag.ID+1), Message: "Tag does not exist"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := tt.article
+			article.Title, article.Content, article.AuthorID = tt.name, "content", 1
+			err := s.CreateArticle(ctx, &article)
+			if !errors.Is(err, validation.ErrValidation) {
+				t.Fatalf("CreateArticle() error = %v, want %v", err, validation.ErrValidation)
+			}
+			details, _ := apperr.From(err).Details.([]validation.FieldError)
+			if !slices.Equal(details, []validation.FieldError{tt.want}) {
+				t.Errorf("CreateArticle() details = %+v, want %+v", details, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateArticle(t *testing.T) {
+	s, store, repos := newArticleService(t)
+	ctx := context.Background()
+	categoryID := mustCategory(t, repos, "Go")
+	gin, gorm := store.AddTag("gin"), store.AddTag("gorm")
+
+	article := &models.Article{Title: "draft", Content: "content", AuthorID: 1, CategoryID: categoryID, Tags: []models.Tag{gin}}
+	if err := s.CreateArticle(ctx, article); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := &models.Article{Title: "invalid", Content: "content", CategoryID: categoryID, Tags: []models.Tag{{BaseModel: models.BaseModel{ID: gorm.ID + 1}}}}
+	if err := s.UpdateArticle(ctx, article.ID, invalid); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("UpdateArticle() with unknown tag error = %v, want %v", err, ErrInvalidTag)
+	}
+	if err := s.UpdateArticle(ctx, article.ID+1, &models.Article{Title: "missing"}); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("UpdateArticle() for unknown article error = %v, want %v", err, ErrArticleNotFound)
+	}
+
+	update := &models.Article{Title: "published", Content: "content", CategoryID: categoryID, Tags: []models.Tag{gorm}, Status: 1}
+	if err := s.UpdateArticle(ctx, article.ID, update); err != nil {
+		t.Fatalf("UpdateArticle() error = %v", err)
+	}
+	got, err := s.GetArticleByID(ctx, article.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "published" || got.Status != 1 {
+		t.Errorf("article = %q status %d, want published", got.Title, got.Status)
+	}
+	if len(got.Tags) != 1 || got.Tags[0].ID != gorm.ID {
+		t.Errorf("article tags = %+v, want [gorm]", got.Tags)
+	}
+}
+
+func TestDeleteArticle(t *testing.T) {
+	s, _, _ := newArticleService(t)
+	ctx := context.Background()
+
+	article := &models.Article{Title: "title", Content: "content", AuthorID: 1}
+	if err := s.CreateArticle(ctx, article); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteArticle(ctx, article.ID); err != nil {
+		t.Fatalf("DeleteArticle() error = %v", err)
+	}
+	if _, err := s.GetArticleByID(ctx, article.ID); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("GetArticleByID() after delete error = %v, want %v", err, ErrArticleNotFound)
+	}
+	if err := s.DeleteArticle(ctx, article.ID); !errors.Is(err, ErrArticleNotFound) {
+		t.Errorf("DeleteArticle() twice error = %v, want %v", err, ErrArticleNotFound)
+	}
+}
+
+func TestGetArticleList(t *testing.T) {
+	s, store, _ := newArticleService(t)
+	ctx := context.Background()
+
+	var ids []uint
+	for _, title := range []string{"first", "second", "third"} {
+		article := &models.Article{Title: title, Content: "content", AuthorID: 1, Status: 1}
+		if err := s.CreateArticle(ctx, article); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, article.ID)
+	}
+	store.AddComment(models.Comment{ArticleID: ids[0], UserID: 1, Content: "comment"})
+
+	// 按评论数降序，评论数相同时按ID降序
+	q := ArticleListQuery{Sort: query.Order{Field: repository.ArticleSortComments, Desc: true}, PageSize: 2}
+	articles, total, err := s.GetArticleList(ctx, q, 1)
+	if err != nil {
+		t.Fatalf("GetArticleList() error = %v", err)
+	}
+	if total != 3 || len(articles) != 2 {
+		t.Fatalf("GetArticleList() = %d articles, total %d", len(articles), total)
+	}
+	if articles[0].ID != ids[0] || articles[0].CommentCount != 1 || articles[1].ID != ids[2] {
+		t.Errorf("GetArticleList() order = %d, %d", articles[0].ID, articles[1].ID)
+	}
+}
+
+// mustArticles 创建若干已发布文章，返回按创建顺序排列的ID
+func mustArticles(t *testing.T, s *ArticleService, n int) []uint {
+	t.Helper()
+	ids := make([]uint, n)
+	for i := range ids {
+		article := &models.Article{Title: "article", Content: "content", AuthorID: 1, Status: 1, ViewCount: i % 2}
+		if err := s.CreateArticle(context.Background(), article); err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = article.ID
+	}
+	return ids
+}
+
+// articleIDs 文章ID列表
+func articleIDs(articles []models.Article) []uint {
+	ids := make([]uint, len(articles))
+	for i := range articles {
+		ids[i] = articles[i].ID
+	}
+	return ids
+}
+
+func TestListArticlesCursor(t *testing.T) {
+	tests := []struct {
+		name string
+		sort query.Order
+		want func(ids []uint) []uint // 期望的完整顺序
+	}{
+		{"default", query.Order{}, func(ids []uint) []uint {
+			return []uint{ids[4], ids[3], ids[2], ids[1], ids[0]}
+		}},
+		{"views ascending", query.Order{Field: repository.ArticleSortViews}, func(ids []uint) []uint {
+			return []uint{ids[0], ids[2], ids[4], ids[1], ids[3]}
+		}},
+		{"views descending", query.Order{Field: repository.ArticleSortViews, Desc: true}, func(ids []uint) []uint {
+			return []uint{ids[3], ids[1], ids[4], ids[2], ids[0]}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newArticleService(t)
+			ctx := context.Background()
+			want := tt.want(mustArticles(t, s, 5))
+
+			// 向后翻到最后一页
+			var (
+				pages   [][]uint
+				cursors []string
+			)
+			q := ArticleListQuery{Sort: tt.sort, PageSize: 2}
+			for {
+				result, err := s.ListArticles(ctx, q)
+				if err != nil {
+					t.Fatalf("ListArticles() error = %v", err)
+				}
+				if (q.Cursor == "") != (result.PrevCursor == "") {
+					t.Errorf("page %d prev cursor = %q", len(pages), result.PrevCursor)
+				}
+				pages = append(pages, articleIDs(result.Articles))
+				cursors = append(cursors, result.PrevCursor)
+				if result.NextCursor == "" {
+					break
+				}
+				q.Cursor = result.NextCursor
+			}
+
+			var got []uint
+			for _, page := range pages {
+				got = append(got, page...)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("forward pages = %v, want %v", pages, want)
+			}
+
+			// 从最后一页向前翻回第一页，每页与向后翻页时一致
+			for i := len(pages) - 1; i > 0; i-- {
+				result, err := s.ListArticles(ctx, ArticleListQuery{Sort: tt.sort, PageSize: 2, Cursor: cursors[i]})
+				if err != nil {
+					t.Fatalf("ListArticles() before error = %v", err)
+				}
+				if ids := articleIDs(result.Articles); !slices.Equal(ids, pages[i-1]) {
+					t.Errorf("page %d backward = %v, want %v", i-1, ids, pages[i-1])
+				}
+				if result.NextCursor == "" || (i-1 == 0) != (result.PrevCursor == "") {
+					t.Errorf("page %d backward cursors: next = %q, prev = %q", i-1, result.NextCursor, result.PrevCursor)
+				}
+			}
+		})
+	}
+}
+
+func TestListArticlesIgnoresNewArticles(t *testing.T) {
+	s, _, _ := newArticleService(t)
+	ctx := context.Background()
+	ids := mustArticles(t, s, 4)
+
+	first, err := s.ListArticles(ctx, ArticleListQuery{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 翻页期间发布的新文章不会让下一页重复出现上一页的内容
+	mustArticles(t, s, 1)
+
+	second, err := s.ListArticles(ctx, ArticleListQuery{PageSize: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := articleIDs(second.Articles); !slices.Equal(got, []uint{ids[1], ids[0]}) {
+		t.Errorf("second page = %v, want %v", got, []uint{ids[1], ids[0]})
+	}
+	if second.NextCursor != "" {
+		t.Errorf("second page next cursor = %q, want none", second.NextCursor)
+	}
+}
+
+func TestListArticlesInvalidCursor(t *testing.T) {
+	s, _, _ := newArticleService(t)
+	ctx := context.Background()
+	mustArticles(t, s, 3)
+
+	first, err := s.ListArticles(ctx, ArticleListQuery{PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _, _ := strings.Cut(first.NextCursor, ".")
+
+	tests := []struct {
+		name   string
+		sort   query.Order
+		cursor string
+	}{
+		{"tampered signature", query.Order{}, data + ".AAAA"},
+		{"not a cursor", query.Order{}, "page-2"},
+		{"different sort", query.Order{Field: repository.ArticleSortViews}, first.NextCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ListArticles(ctx, ArticleListQuery{Sort: tt.sort, PageSize: 1, Cursor: tt.cursor})
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("ListArticles() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestListArticlesFilter(t *testing.T) {
+	s, store, _ := newArticleService(t)
+	ctx := context.Background()
+	gin, gorm := store.AddTag("gin"), store.AddTag("gorm")
+
+	create := func(title string, status int, tags ...models.Tag) uint {
+		article := &models.Article{Title: title, Content: "content", AuthorID: 1, Status: status, Tags: tags}
+		if err := s.CreateArticle(ctx, article); err != nil {
+			t.Fatal(err)
+		}
+		return article.ID
+	}
+	both := create("Gin and GORM", 1, gin, gorm)
+	ginOnly := create("100% Gin", 1, gin)
+	draft := create("Draft about GORM", 0, gorm)
+
+	published := 1
+	tests := []struct {
+		name   string
+		filter repository.ArticleFilter
+		want   []uint
+	}{
+		{"keyword ignores case", repository.ArticleFilter{Keyword: "gorm"}, []uint{draft, both}},
+		{"keyword wildcard is literal", repository.ArticleFilter{Keyword: "100%"}, []uint{ginOnly}},
+		{"status", repository.ArticleFilter{Status: &published, Keyword: "gorm"}, []uint{both}},
+		{"any tag", repository.ArticleFilter{TagIDs: []uint{gin.ID, gorm.ID}}, []uint{draft, ginOnly, both}},
+		{"all tags", repository.ArticleFilter{TagIDs: []uint{gin.ID, gorm.ID}, MatchAllTags: true}, []uint{both}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.ListArticles(ctx, ArticleListQuery{ArticleFilter: tt.filter, WithTotal: true})
+			if err != nil {
+				t.Fatalf("ListArticles() error = %v", err)
+			}
+			if got := articleIDs(result.Articles); !slices.Equal(got, tt.want) {
+				t.Errorf("ListArticles() = %v, want %v", got, tt.want)
+			}
+			if result.Total == nil || *result.Total != int64(len(tt.want)) {
+				t.Errorf("ListArticles() total = %v, want %d", result.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestArticleListSelection(t *testing.T) {
+	q := ArticleListQuery{
+		Sort:       query.Order{Field: repository.ArticleSortViews, Desc: true},
+		Fields:     []string{"title", "author"},
+		WithStatus: true,
+	}
+	sel := q.selection()
+
+	// 排序列总是查询，用于生成游标
+	want := []string{"view_count", "id", "status", "title", ArticleFields["author"]}
+	if !slices.Equal(sel.Columns, want) {
+		t.Errorf("selection() columns = %v, want %v", sel.Columns, want)
+	}
+	if !sel.Author || sel.Category || sel.Tags {
+		t.Errorf("selection() preloads author = %v, category = %v, tags = %v", sel.Author, sel.Category, sel.Tags)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+)
+
+// maxBackgroundMails 同时在后台发送的邮件数上限
+const maxBackgroundMails = 16
+
+// mailWorkers 在后台发送邮件，请求结束后继续执行
+// 限制同时发送的数量，SMTP 服务器变慢时多出的邮件直接放弃，不会无限堆积 goroutine 和连接
+type mailWorkers struct {
+	slots chan struct{}
+}
+
+// newMailWorkers 创建后台邮件发送，limit 为同时发送的数量上限
+func newMailWorkers(limit int) *mailWorkers {
+	return &mailWorkers{slots: make(chan struct{}, limit)}
+}
+
+// run 在后台执行 send，超过 mail.SendTimeout 后取消，失败时记录日志
+// 正在发送的邮件已达上限时放弃并返回 false
+func (w *mailWorkers) run(ctx context.Context, name string, send func(ctx context.Context) error, fields ...zap.Field) bool {
+	fields = append(fields, zap.String("mail", name))
+	select {
+	case w.slots <- struct{}{}:
+	default:
+		logger.WithContext(ctx).Warn("后台发送的邮件已达上限，放弃发送", fields...)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mail.SendTimeout)
+	go func() {
+		defer func() {
+			cancel()
+			<-w.slots
+		}()
+		if err := send(ctx); err != nil {
+			logger.WithContext(ctx).Warn("后台发送邮件失败", append(fields, zap.Error(err))...)
+		}
+	}()
+	return true
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+)
+
+func TestMailWorkersLimit(t *testing.T) {
+	w := newMailWorkers(2)
+	release := make(chan struct{})
+	started := make(chan time.Time, 2)
+	block := func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		started <- deadline
+		<-release
+		return nil
+	}
+
+	// 请求的 context 取消后后台发送仍继续，并带有发送超时
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 2; i++ {
+		if !w.run(ctx, "test", block) {
+			t.Fatalf("run() #%d dropped below the limit", i+1)
+		}
+	}
+	cancel()
+	for i := 0; i < 2; i++ {
+		select {
+		case deadline := <-started:
+			if deadline.IsZero() || time.Until(deadline) > mail.SendTimeout {
+				t.Errorf("background deadline = %v, want within %v", deadline, mail.SendTimeout)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("background send did not start")
+		}
+	}
+
+	if w.run(context.Background(), "test", block) {
+		t.Error("run() above the limit was not dropped")
+	}
+
+	// 发送结束后释放名额
+	close(release)
+	done := make(chan struct{})
+	deadline := time.Now().Add(time.Second)
+	for !w.run(context.Background(), "test", func(context.Context) error {
+		close(done)
+		return errors.New("failed")
+	}) {
+		if time.Now().After(deadline) {
+			t.Fatal("slots were not released after sending")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	<-done
+}
@@ -1,33 +1,36 @@
 package services
 
 import (
+	"context"
 	"errors"
 
-	"gorm.io/gorm"
-
 	"github.com/xiaoxin/blog-backend/internal/models"
-	"github.com/xiaoxin/blog-backend/pkg/database"
+	"github.com/xiaoxin/blog-backend/internal/repository"
 )
 
 // CategoryService 分类服务
-type CategoryService struct{}
+type CategoryService struct {
+	categories repository.CategoryRepository
+	articles   repository.ArticleRepository
+}
 
 // NewCategoryService 创建分类服务实例
-func NewCategoryService() *CategoryService {
-	return &CategoryService{}
+func NewCategoryService(categories repository.CategoryRepository, articles repository.ArticleRepository) *CategoryService {
+	return &CategoryService{
+		categories: categories,
+		articles:   articles,
+	}
 }
 
 // CreateCategory 创建分类
-func (s *CategoryService) CreateCategory(name, description string, sort int) (*models.Category, error) {
-	db := database.GetDB()
-
+func (s *CategoryService) CreateCategory(ctx context.Context, name, description string, sort int) (*models.Category, error) {
 	// 检查分类名是否存在
-	var count int64
-	if err := db.Model(&models.Category{}).Where("name = ?", name).Count(&count).Error; err != nil {
+	exists, err := s.categories.ExistsByName(ctx, name)
+	if err != nil {
 		return nil, err
 	}
-	if count > 0 {
-		return nil, errors.New("分类名已存在")
+	if exists {
+		return nil, ErrCategoryExists
 	}
 
 	category := &models.Category{
@@ -36,7 +39,7 @@ func (s *CategoryService) CreateCategory(name, description string, sort int) (*m
 		Sort:        sort,
 	}
 
-	if err := db.Create(category).Error; err != nil {
+	if err := s.categories.Create(ctx, category); err != nil {
 		return nil, err
 	}
 
@@ -44,53 +47,36 @@ func (s *CategoryService) CreateCategory(name, description string, sort int) (*m
 }
 
 // GetCategoryByID 根据ID获取分类
-func (s *CategoryService) GetCategoryByID(id uint) (*models.Category, error) {
-	db := database.GetDB()
-
-	var category models.Category
-	if err := db.First(&category, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("分类不存在")
+func (s *CategoryService) GetCategoryByID(ctx context.Context, id uint) (*models.Category, error) {
+	category, err := s.categories.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCategoryNotFound
 		}
 		return nil, err
 	}
 
-	return &category, nil
+	return category, nil
 }
 
 // GetCategoryList 获取分类列表
-func (s *CategoryService) GetCategoryList() ([]models.Category, error) {
-	db := database.GetDB()
-
-	var categories []models.Category
-	if err := db.Order("sort ASC, id DESC").Find(&categories).Error; err != nil {
-		return nil, err
-	}
-
-	return categories, nil
+func (s *CategoryService) GetCategoryList(ctx context.Context) ([]models.Category, error) {
+	return s.categories.List(ctx)
 }
 
 // UpdateCategory 更新分类
-func (s *CategoryService) UpdateCategory(id uint, name, description string, sort int) error {
-	db := database.GetDB()
-
-	// 检查分类是否存在
-	var category models.Category
-	if err := db.First(&category, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("分类不存在")
-		}
-		return err
-	}
-
-	// 更新分类
-	updates := map[string]interface{}{
-		"name":        name,
-		"description": description,
-		"sort":        sort,
+func (s *CategoryService) UpdateCategory(ctx context.Context, id uint, name, description string, sort int) error {
+	category := &models.Category{
+		BaseModel:   models.BaseModel{ID: id},
+		Name:        name,
+		Description: description,
+		Sort:        sort,
 	}
 
-	if err := db.Model(&models.Category{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+	if err := s.categories.Update(ctx, category); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCategoryNotFound
+		}
 		return err
 	}
 
@@ -98,29 +84,26 @@ func (s *CategoryService) UpdateCategory(id uint, name, description string, sort
 }
 
 // DeleteCategory 删除分类
-func (s *CategoryService) DeleteCategory(id uint) error {
-	db := database.GetDB()
-
+func (s *CategoryService) DeleteCategory(ctx context.Context, id uint) error {
 	// 检查分类是否存在
-	var category models.Category
-	if err := db.First(&category, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("分类不存在")
-		}
+	if _, err := s.GetCategoryByID(ctx, id); err != nil {
 		return err
 	}
 
 	// 检查是否有文章使用该分类
-	var count int64
-	if err := db.Model(&models.Article{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+	count, err := s.articles.CountByCategory(ctx, id)
+	if err != nil {
 		return err
 	}
 	if count > 0 {
-		return errors.New("该分类下还有文章，无法删除")
+		return ErrCategoryNotEmpty
 	}
 
 	// 删除分类
-	if err := db.Delete(&category).Error; err != nil {
+	if err := s.categories.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrCategoryNotFound
+		}
 		return err
 	}
 
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+)
+
+// CommentService 评论服务
+type CommentService struct {
+	comments repository.CommentRepository
+	users    repository.UserRepository
+}
+
+// NewCommentService 创建评论服务实例
+func NewCommentService(comments repository.CommentRepository, users repository.UserRepository) *CommentService {
+	return &CommentService{
+		comments: comments,
+		users:    users,
+	}
+}
+
+// ListUserComments 分页获取用户的评论及总数，按发表时间倒序
+func (s *CommentService) ListUserComments(ctx context.Context, userID uint, page, pageSize int) ([]models.Comment, int64, error) {
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, 0, ErrUserNotFound
+		}
+		return nil, 0, err
+	}
+
+	pageSize = clampPageSize(pageSize)
+	if page < 1 {
+		page = 1
+	}
+
+	comments, err := s.comments.ListByUser(ctx, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.comments.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// cooldown 按用户限制操作频率，两次操作之间至少间隔 interval
+type cooldown struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	last map[uint]time.Time
+}
+
+// newCooldown 创建频率限制
+func newCooldown(interval time.Duration) *cooldown {
+	return &cooldown{interval: interval, last: make(map[uint]time.Time)}
+}
+
+// allow 判断是否已超过间隔，允许时记录本次操作时间
+func (c *cooldown) allow(id uint, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.last[id]; ok && now.Sub(last) < c.interval {
+		return false
+	}
+	for userID, last := range c.last {
+		if now.Sub(last) >= c.interval {
+			delete(c.last, userID)
+		}
+	}
+	c.last[id] = now
+	return true
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+)
+
+// verificationCooldown 重新发送验证邮件的最小间隔
+const verificationCooldown = time.Minute
+
+// VerifyEmail 根据验证链接中的令牌验证邮箱
+// 令牌绑定了待验证的邮箱，用户之后又修改了邮箱时旧链接失效
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := pkgjwt.ParseActionToken(pkgjwt.PurposeVerifyEmail, token)
+	if err != nil || claims.Value == "" {
+		return ErrInvalidVerificationToken
+	}
+
+	user, err := s.users.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	var update repository.UserUpdate
+	switch claims.Value {
+	case user.PendingEmail:
+		update.Email = &claims.Value
+		update.PendingEmail = new(string)
+		update.EmailVerifiedAt = &now
+	case user.Email:
+		if user.EmailVerified() {
+			return nil
+		}
+		update.EmailVerifiedAt = &now
+	default:
+		return ErrInvalidVerificationToken
+	}
+
+	return s.update(ctx, user.ID, update)
+}
+
+// ResendVerification 重新发送验证邮件，有待验证的新邮箱时发往新邮箱
+func (s *UserService) ResendVerification(ctx context.Context, id uint) error {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	email := user.PendingEmail
+	if email == "" {
+		if user.Email == "" {
+			return ErrEmailNotSet
+		}
+		if user.EmailVerified() {
+			return ErrEmailAlreadyVerified
+		}
+		email = user.Email
+	}
+
+	if !s.verifyCooldown.allow(id, time.Now()) {
+		return ErrVerificationCooldown
+	}
+	return s.sendVerification(ctx, user, email)
+}
+
+// CheckEmailVerified 检查用户邮箱是否已验证，未验证时返回 ErrEmailNotVerified
+func (s *UserService) CheckEmailVerified(ctx context.Context, id uint) error {
+	user, err := s.currentUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if !user.EmailVerified() {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// sendVerification 向指定邮箱发送验证链接，邮件语言优先使用用户的语言偏好
+func (s *UserService) sendVerification(ctx context.Context, user *models.User, email string) error {
+	cfg := config.Get().EmailVerification
+	ttl := cfg.GetTokenTTL()
+
+	token, err := pkgjwt.GenerateActionToken(pkgjwt.PurposeVerifyEmail, user.ID, email, ttl)
+	if err != nil {
+		return err
+	}
+	link, err := url.Parse(cfg.VerifyURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	locale := user.Locale
+	if locale == "" {
+		locale = i18n.FromContext(ctx)
+	}
+	name := user.Nickname
+	if name == "" {
+		name = user.Username
+	}
+
+	return mail.Send(ctx, &mail.Message{
+		To:      email,
+		Subject: i18n.T(locale, "email_verify_subject"),
+		Text:    i18n.T(locale, "email_verify_body", name, int(ttl.Hours()), link.String()),
+	})
+}
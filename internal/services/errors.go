@@ -0,0 +1,73 @@
+package services
+
+import (
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+)
+
+// 用户相关错误
+var (
+	ErrUserNotFound       = apperr.NotFound("user_not_found", "用户不存在")
+	ErrUsernameTaken      = apperr.Conflict("username_taken", "用户名已存在")
+	ErrEmailTaken         = apperr.Conflict("email_taken", "邮箱已被使用")
+	ErrInvalidRole        = apperr.BadRequest("invalid_role", "无效的用户角色")
+	ErrInvalidLocale      = apperr.BadRequest("invalid_locale", "不支持的语言")
+	ErrInvalidCredentials = apperr.Unauthorized("invalid_credentials", "用户名或密码错误")
+	ErrUserDisabled       = apperr.Forbidden("user_disabled", "用户已被禁用")
+	ErrWrongPassword      = apperr.BadRequest("wrong_password", "原密码错误")
+	ErrTokenRevoked       = apperr.Unauthorized("token_revoked", "登录已失效，请重新登录")
+	ErrInvalidBanExpiry   = apperr.BadRequest("invalid_ban_expiry", "封禁到期时间必须晚于当前时间")
+	ErrCannotModifySelf   = apperr.BadRequest("cannot_modify_self", "不能对自己执行该操作")
+	ErrLoginLockNotFound  = apperr.NotFound("login_lock_not_found", "该用户名未被锁定")
+)
+
+// 邮箱验证相关错误
+var (
+	ErrEmailNotVerified         = apperr.Forbidden("email_not_verified", "请先验证邮箱")
+	ErrEmailNotSet              = apperr.BadRequest("email_not_set", "尚未设置邮箱")
+	ErrEmailAlreadyVerified     = apperr.BadRequest("email_already_verified", "邮箱已验证")
+	ErrInvalidVerificationToken = apperr.BadRequest("invalid_verification_token", "验证链接无效或已过期")
+	ErrVerificationCooldown     = apperr.New(apperr.ErrTooManyRequests, "verification_cooldown", "验证邮件发送过于频繁，请稍后再试")
+	ErrInvalidResetToken        = apperr.BadRequest("invalid_reset_token", "重置链接无效或已过期")
+	ErrPasswordResetUnavailable = apperr.NotFound("password_reset_unavailable", "找回密码功能未启用")
+)
+
+// 两步验证相关错误
+var (
+	ErrTwoFactorAlreadyEnabled = apperr.BadRequest("two_factor_already_enabled", "两步验证已启用")
+	ErrTwoFactorNotEnabled     = apperr.BadRequest("two_factor_not_enabled", "尚未启用两步验证")
+	ErrTwoFactorNotSetup       = apperr.BadRequest("two_factor_not_setup", "请先获取两步验证密钥")
+	ErrInvalidTwoFactorCode    = apperr.BadRequest("invalid_two_factor_code", "动态码或恢复码错误")
+	ErrInvalidMFAToken         = apperr.Unauthorized("invalid_mfa_token", "两步验证已超时，请重新登录")
+	ErrTwoFactorRequired       = apperr.Forbidden("two_factor_required", "请先启用两步验证")
+	ErrTwoFactorUnavailable    = apperr.NotFound("two_factor_unavailable", "两步验证功能未启用")
+)
+
+// 第三方登录相关错误
+var (
+	ErrOIDCProviderNotFound  = apperr.NotFound("oidc_provider_not_found", "不支持该登录方式")
+	ErrInvalidOAuthState     = apperr.BadRequest("invalid_oauth_state", "登录请求无效或已过期，请重新登录")
+	ErrOIDCAuthFailed        = apperr.Unauthorized("oidc_auth_failed", "第三方登录验证失败")
+	ErrOIDCUnavailable       = apperr.New(apperr.ErrBadGateway, "oidc_unavailable", "第三方登录服务暂时不可用，请稍后再试")
+	ErrIdentityNotLinked     = apperr.Forbidden("identity_not_linked", "该第三方账号未关联本站账号，请登录后在个人资料中关联")
+	ErrIdentityEmailTaken    = apperr.Conflict("identity_email_taken", "该邮箱已注册，请使用密码登录后在个人资料中关联第三方账号")
+	ErrIdentityTaken         = apperr.Conflict("identity_taken", "该第三方账号已关联其他账号")
+	ErrIdentityProviderTaken = apperr.Conflict("identity_provider_linked", "已关联该登录方式的账号，请先解除关联")
+	ErrIdentityNotFound      = apperr.NotFound("identity_not_found", "未关联该登录方式")
+	ErrLastLoginMethod       = apperr.BadRequest("last_login_method", "这是唯一的登录方式，请先设置密码再解除关联")
+)
+
+// 文章相关错误
+var (
+	ErrArticleNotFound = apperr.NotFound("article_not_found", "文章不存在")
+	ErrInvalidCursor   = apperr.BadRequest("invalid_cursor", "无效的分页游标")
+	ErrInvalidCategory = validation.ErrValidation.WithMessageID("invalid_category") // details 中为 category_id 字段的错误
+	ErrInvalidTag      = validation.ErrValidation.WithMessageID("invalid_tag")      // details 中为 tag_ids 字段的错误
+)
+
+// 分类相关错误
+var (
+	ErrCategoryNotFound = apperr.NotFound("category_not_found", "分类不存在")
+	ErrCategoryExists   = apperr.Conflict("category_exists", "分类名已存在")
+	ErrCategoryNotEmpty = apperr.Conflict("category_not_empty", "该分类下还有文章，无法删除")
+)
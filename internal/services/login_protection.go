@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
+)
+
+// loginAttempt 一次登录尝试的防暴力破解状态
+type loginAttempt struct {
+	enabled  bool
+	blocked  bool // 账号已锁定或IP已暂停
+	username string
+	ip       string
+	cfg      config.LoginProtectionConfig
+}
+
+// loginGuard 检查用户名是否被锁定、IP 失败次数是否超限
+// 存储不可用时只记录日志，不影响登录
+func (s *UserService) loginGuard(ctx context.Context, username, ip string) loginAttempt {
+	cfg := config.Get()
+	if s.attempts == nil || cfg == nil || !cfg.LoginProtection.Enabled {
+		return loginAttempt{}
+	}
+
+	a := loginAttempt{enabled: true, username: normalizeUsername(username), ip: ip, cfg: cfg.LoginProtection}
+	if _, err := s.attempts.LockedUntil(ctx, a.username); err == nil {
+		a.blocked = true
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		logger.WithContext(ctx).Warn("查询登录锁定失败", zap.Error(err))
+	}
+
+	if ip != "" {
+		n, err := s.attempts.Failures(ctx, "ip:"+ip)
+		if err != nil {
+			logger.WithContext(ctx).Warn("查询登录失败次数失败", zap.Error(err))
+		}
+		if n >= a.cfg.IPMaxAttempts {
+			a.blocked = true
+		}
+	}
+	return a
+}
+
+// loginFailed 记录一次失败并返回 ErrInvalidCredentials
+// 用户名失败次数达到上限时锁定账号；响应按失败次数指数延迟，锁定期间按上限延迟，两种情况的响应相同
+func (s *UserService) loginFailed(ctx context.Context, a loginAttempt, user *models.User) error {
+	if !a.enabled {
+		metrics.UserLogins.WithLabelValues("failure").Inc()
+		return ErrInvalidCredentials
+	}
+	if a.blocked {
+		metrics.UserLogins.WithLabelValues("locked").Inc()
+		wait(ctx, a.cfg.Delay(a.cfg.MaxAttempts))
+		return ErrInvalidCredentials
+	}
+	metrics.UserLogins.WithLabelValues("failure").Inc()
+
+	failures, err := s.attempts.AddFailure(ctx, "user:"+a.username, a.cfg.Window())
+	if err != nil {
+		logger.WithContext(ctx).Warn("记录登录失败次数失败", zap.Error(err))
+	}
+	if failures >= a.cfg.MaxAttempts {
+		s.lockLogin(ctx, a, user)
+	}
+
+	// 延迟按用户名和IP中较多的失败次数计算，同一IP尝试不同用户名也会逐渐变慢
+	delayed := failures
+	if a.ip != "" {
+		n, err := s.attempts.AddFailure(ctx, "ip:"+a.ip, a.cfg.Window())
+		if err != nil {
+			logger.WithContext(ctx).Warn("记录登录失败次数失败", zap.Error(err))
+		}
+		delayed = max(delayed, n)
+	}
+	wait(ctx, a.cfg.Delay(delayed))
+	return ErrInvalidCredentials
+}
+
+// loginSucceeded 登录成功后清零用户名的失败次数，IP 的失败次数保留到窗口结束
+func (s *UserService) loginSucceeded(ctx context.Context, a loginAttempt) {
+	if !a.enabled {
+		return
+	}
+	if err := s.attempts.ClearFailures(ctx, "user:"+a.username); err != nil {
+		logger.WithContext(ctx).Warn("清除登录失败次数失败", zap.Error(err))
+	}
+}
+
+// lockLogin 锁定用户名并清零失败次数，用户存在且邮箱已验证时发送通知邮件
+func (s *UserService) lockLogin(ctx context.Context, a loginAttempt, user *models.User) {
+	log := logger.WithContext(ctx)
+	until := time.Now().Add(a.cfg.LockDuration())
+	if err := s.attempts.Lock(ctx, a.username, until); err != nil {
+		log.Warn("锁定账号失败", zap.String("username", a.username), zap.Error(err))
+		return
+	}
+	if err := s.attempts.ClearFailures(ctx, "user:"+a.username); err != nil {
+		log.Warn("清除登录失败次数失败", zap.Error(err))
+	}
+	log.Info("登录失败次数过多，账号已锁定", zap.String("username", a.username), zap.String("ip", a.ip), zap.Time("until", until))
+
+	if user == nil || !user.EmailVerified() {
+		return
+	}
+	s.background.run(ctx, "login_locked", func(ctx context.Context) error {
+		return s.sendLockNotice(ctx, user, a)
+	}, zap.Uint("user_id", user.ID))
+}
+
+// sendLockNotice 通知用户账号因登录失败次数过多被锁定
+func (s *UserService) sendLockNotice(ctx context.Context, user *models.User, a loginAttempt) error {
+	locale := user.Locale
+	if locale == "" {
+		locale = i18n.FromContext(ctx)
+	}
+	name := user.Nickname
+	if name == "" {
+		name = user.Username
+	}
+	ip := a.ip
+	if ip == "" {
+		ip = "-"
+	}
+
+	return mail.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "account_locked_subject"),
+		Text:    i18n.T(locale, "account_locked_body", name, a.cfg.MaxAttempts, ip, a.cfg.LockMinutes),
+	})
+}
+
+// ListLoginLocks 全部未到期的登录锁定
+func (s *UserService) ListLoginLocks(ctx context.Context) ([]repository.LoginLock, error) {
+	if s.attempts == nil {
+		return []repository.LoginLock{}, nil
+	}
+	return s.attempts.ListLocks(ctx)
+}
+
+// UnlockLogin 解除用户名的登录锁定并清零失败次数
+func (s *UserService) UnlockLogin(ctx context.Context, username string) error {
+	if s.attempts == nil {
+		return ErrLoginLockNotFound
+	}
+
+	username = normalizeUsername(username)
+	if err := s.attempts.Unlock(ctx, username); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrLoginLockNotFound
+		}
+		return err
+	}
+	return s.attempts.ClearFailures(ctx, "user:"+username)
+}
+
+// dummyPasswordHash 用户不存在时用于比较的密码哈希，代价与真实密码相同
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// normalizeUsername 失败计数和锁定按不区分大小写的用户名统计
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// wait 等待指定时间，请求取消时提前返回
+func wait(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// enableLoginProtection 启用登录保护，延迟缩短到毫秒级
+func enableLoginProtection(t *testing.T, maxAttempts, ipMaxAttempts int) {
+	t.Helper()
+	setConfig(t, func(c *config.Config) {
+		c.LoginProtection = config.LoginProtectionConfig{
+			Enabled:       true,
+			MaxAttempts:   maxAttempts,
+			LockMinutes:   15,
+			IPMaxAttempts: ipMaxAttempts,
+			WindowMinutes: 15,
+			BaseDelayMS:   1,
+			MaxDelayMS:    4,
+		}
+	})
+}
+
+// mustFailLogin 登录应返回与密码错误完全相同的错误
+func mustFailLogin(t *testing.T, s *UserService, username, password, ip string) {
+	t.Helper()
+	_, err := s.Login(context.Background(), username, password, ip)
+	if err != ErrInvalidCredentials {
+		t.Fatalf("Login(%q, %q) error = %#v, want %v", username, password, err, ErrInvalidCredentials)
+	}
+}
+
+func TestLoginLocksAfterMaxAttempts(t *testing.T) {
+	enableLoginProtection(t, 3, 100)
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	if _, err := s.CreateUser(ctx, "alice", "password1", "alice@example.com", "", models.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		mustFailLogin(t, s, "alice", "wrong", "10.0.0.1")
+	}
+
+	// 锁定后密码正确也返回同样的错误，用户名不区分大小写
+	mustFailLogin(t, s, "alice", "password1", "10.0.0.2")
+	mustFailLogin(t, s, " ALICE ", "password1", "10.0.0.2")
+
+	locks, err := s.ListLoginLocks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 1 || locks[0].Username != "alice" || !locks[0].LockedUntil.After(time.Now().Add(14*time.Minute)) {
+		t.Errorf("ListLoginLocks() = %+v, want alice locked for 15 minutes", locks)
+	}
+
+	// 邮箱已验证的用户收到锁定通知
+	if !mailer.wait(time.Second) {
+		t.Fatal("no lock notice sent")
+	}
+	if msgs := mailer.messages(); msgs[0].To != "alice@example.com" {
+		t.Errorf("lock notice sent to %q", msgs[0].To)
+	}
+}
+
+func TestLockNoticeDroppedWhenBusy(t *testing.T) {
+	enableLoginProtection(t, 2, 100)
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	if _, err := s.CreateUser(ctx, "alice", "password1", "alice@example.com", "", models.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+
+	// 后台邮件已达上限时仍然锁定账号，只是不发送通知
+	for i := 0; i < cap(s.background.slots); i++ {
+		s.background.slots <- struct{}{}
+	}
+	for i := 0; i < 2; i++ {
+		mustFailLogin(t, s, "alice", "wrong", "10.0.0.1")
+	}
+	locks, err := s.ListLoginLocks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 1 || locks[0].Username != "alice" {
+		t.Errorf("ListLoginLocks() = %+v, want alice locked", locks)
+	}
+	if mailer.wait(100 * time.Millisecond) {
+		t.Errorf("lock notice sent while background mail is full: %v", mailer.messages())
+	}
+}
+
+func TestLoginFailuresAreIndistinguishable(t *testing.T) {
+	enableLoginProtection(t, 2, 100)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	mustRegister(t, s, "bob", "password1", "")
+	if err := s.BanUser(ctx, id, "spam", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不存在的用户名同样计数并锁定
+	mustFailLogin(t, s, "nobody", "password1", "")
+	mustFailLogin(t, s, "nobody", "password1", "")
+	mustFailLogin(t, s, "nobody", "password1", "")
+
+	// 被锁定的用户即使密码正确也不透露封禁状态
+	mustFailLogin(t, s, "alice", "wrong", "")
+	mustFailLogin(t, s, "alice", "wrong", "")
+	mustFailLogin(t, s, "alice", "password1", "")
+
+	mustFailLogin(t, s, "bob", "wrong", "")
+	if _, err := s.Login(ctx, "bob", "password1", ""); err != nil {
+		t.Errorf("Login() before reaching the limit error = %v", err)
+	}
+}
+
+func TestLoginSuccessClearsFailures(t *testing.T) {
+	enableLoginProtection(t, 3, 100)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	mustRegister(t, s, "alice", "password1", "")
+
+	mustFailLogin(t, s, "alice", "wrong", "")
+	mustFailLogin(t, s, "alice", "wrong", "")
+	if _, err := s.Login(ctx, "alice", "password1", ""); err != nil {
+		t.Fatal(err)
+	}
+	mustFailLogin(t, s, "alice", "wrong", "")
+	mustFailLogin(t, s, "alice", "wrong", "")
+	if _, err := s.Login(ctx, "alice", "password1", ""); err != nil {
+		t.Errorf("Login() after success reset the counter error = %v", err)
+	}
+}
+
+func TestLoginBlocksIP(t *testing.T) {
+	enableLoginProtection(t, 100, 3)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	mustRegister(t, s, "alice", "password1", "")
+
+	// 同一IP尝试不同的用户名
+	for _, username := range []string{"bob", "carol", "dave"} {
+		mustFailLogin(t, s, username, "wrong", "10.0.0.1")
+	}
+	mustFailLogin(t, s, "alice", "password1", "10.0.0.1")
+
+	if _, err := s.Login(ctx, "alice", "password1", "10.0.0.2"); err != nil {
+		t.Errorf("Login() from another IP error = %v", err)
+	}
+}
+
+func TestUnlockLogin(t *testing.T) {
+	enableLoginProtection(t, 2, 100)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	mustRegister(t, s, "alice", "password1", "")
+
+	if err := s.UnlockLogin(ctx, "alice"); !errors.Is(err, ErrLoginLockNotFound) {
+		t.Errorf("UnlockLogin() without lock error = %v, want %v", err, ErrLoginLockNotFound)
+	}
+
+	mustFailLogin(t, s, "alice", "wrong", "")
+	mustFailLogin(t, s, "alice", "wrong", "")
+	mustFailLogin(t, s, "alice", "password1", "")
+
+	if err := s.UnlockLogin(ctx, "Alice"); err != nil {
+		t.Fatalf("UnlockLogin() error = %v", err)
+	}
+	if locks, _ := s.ListLoginLocks(ctx); len(locks) != 0 {
+		t.Errorf("ListLoginLocks() after unlock = %+v", locks)
+	}
+
+	// 解锁后失败次数从零开始计算
+	mustFailLogin(t, s, "alice", "wrong", "")
+	if _, err := s.Login(ctx, "alice", "password1", ""); err != nil {
+		t.Errorf("Login() after unlock error = %v", err)
+	}
+}
+
+func TestLoginFailureDelay(t *testing.T) {
+	setConfig(t, func(c *config.Config) {
+		c.LoginProtection = config.LoginProtectionConfig{
+			Enabled:       true,
+			MaxAttempts:   2,
+			LockMinutes:   15,
+			IPMaxAttempts: 100,
+			WindowMinutes: 15,
+			BaseDelayMS:   20,
+			MaxDelayMS:    40,
+		}
+	})
+	s, _ := newUserService(t)
+	mustRegister(t, s, "alice", "password1", "")
+
+	// 第一次失败延迟基础时间，锁定后按上限延迟
+	for _, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond} {
+		start := time.Now()
+		mustFailLogin(t, s, "alice", "wrong", "")
+		if elapsed := time.Since(start); elapsed < want {
+			t.Errorf("failed login took %v, want at least %v", elapsed, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/repository/memory"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	logger.SugaredLogger = logger.Logger.Sugar()
+	pkgjwt.InitJWT("test-secret")
+	config.Set(testConfig())
+	os.Exit(m.Run())
+}
+
+// testConfig 测试使用的配置，未启用登录保护
+func testConfig() *config.Config {
+	return &config.Config{
+		App: config.AppConfig{Name: "blog-test"},
+		JWT: config.JWTConfig{Secret: "test-secret", ExpireHours: 1, RefreshExpireHours: 24},
+		EmailVerification: config.EmailVerificationConfig{
+			VerifyURL: "http://localhost/verify-email",
+		},
+		PasswordReset: config.PasswordResetConfig{
+			ResetURL: "http://localhost/reset-password",
+		},
+	}
+}
+
+// setConfig 在测试期间修改配置，测试结束后恢复
+func setConfig(t *testing.T, modify func(c *config.Config)) {
+	t.Helper()
+	prev := config.Get()
+	c := testConfig()
+	modify(c)
+	config.Set(c)
+	t.Cleanup(func() { config.Set(prev) })
+}
+
+// fakeMailer 记录发送的邮件
+type fakeMailer struct {
+	mu   sync.Mutex
+	msgs []mail.Message
+	sent chan struct{}
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.mu.Lock()
+	m.msgs = append(m.msgs, *msg)
+	m.mu.Unlock()
+	select {
+	case m.sent <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// messages 已发送的邮件
+func (m *fakeMailer) messages() []mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mail.Message(nil), m.msgs...)
+}
+
+// wait 等待下一封邮件，超时返回 false，用于后台发送的邮件
+func (m *fakeMailer) wait(timeout time.Duration) bool {
+	select {
+	case <-m.sent:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// drain 丢弃已到达的邮件通知
+func (m *fakeMailer) drain() {
+	for {
+		select {
+		case <-m.sent:
+		default:
+			return
+		}
+	}
+}
+
+// useMailer 在测试期间替换全局邮件发送器
+func useMailer(t *testing.T) *fakeMailer {
+	t.Helper()
+	m := &fakeMailer{sent: make(chan struct{}, 16)}
+	mail.SetMailer(m)
+	t.Cleanup(func() { mail.SetMailer(nil) })
+	return m
+}
+
+// newUserService 基于内存仓储创建用户服务
+func newUserService(t *testing.T) (*UserService, *repository.Repositories) {
+	t.Helper()
+	repos := memory.NewRepositories()
+	return NewUserService(repos.Users, repos.RecoveryCodes, repos.PasswordResets, repos.LoginAttempts), repos
+}
+
+// mustRegister 注册用户，失败时终止测试
+func mustRegister(t *testing.T, s *UserService, username, password, email string) uint {
+	t.Helper()
+	user, err := s.Register(context.Background(), username, password, email, "")
+	if err != nil {
+		t.Fatalf("Register(%q) error = %v", username, err)
+	}
+	return user.ID
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
+)
+
+// oidcHTTPTimeout 请求身份提供方（发现文档、签名公钥、令牌端点）的超时时间
+const oidcHTTPTimeout = 10 * time.Second
+
+// OIDCProvider 可用的第三方登录方式
+type OIDCProvider struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+// oidcClaims ID Token 中使用的声明
+type oidcClaims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	Picture           string `json:"picture"`
+}
+
+// OIDCService OpenID Connect 第三方登录服务
+// 使用授权码模式 + PKCE，ID Token 通过发现文档中的签名公钥（JWKS）验证，验证通过后签发本站令牌
+type OIDCService struct {
+	users      *UserService
+	identities repository.IdentityRepository
+	states     repository.OAuthStateStore
+	uow        repository.UnitOfWork
+	client     *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*oidc.Provider // 签发者地址 -> 发现结果，签名公钥由其按需刷新
+	discovery singleflight.Group        // 合并同一签发者的并发发现请求
+}
+
+// NewOIDCService 创建第三方登录服务实例
+func NewOIDCService(users *UserService, identities repository.IdentityRepository, states repository.OAuthStateStore, uow repository.UnitOfWork) *OIDCService {
+	return &OIDCService{
+		users:      users,
+		identities: identities,
+		states:     states,
+		uow:        uow,
+		client:     &http.Client{Timeout: oidcHTTPTimeout},
+		providers:  make(map[string]*oidc.Provider),
+	}
+}
+
+// Providers 已配置的第三方登录方式
+func (s *OIDCService) Providers() []OIDCProvider {
+	providers := []OIDCProvider{}
+	for _, p := range config.Get().OIDC.Providers {
+		providers = append(providers, OIDCProvider{Name: p.Name, DisplayName: p.GetDisplayName()})
+	}
+	return providers
+}
+
+// AuthURL 创建授权请求，返回身份提供方的授权页面地址
+// userID 不为 0 时为已登录用户关联身份，回调需由同一用户调用 Link
+func (s *OIDCService) AuthURL(ctx context.Context, name string, userID uint) (string, error) {
+	cfg := config.Get().OIDC
+	p, ok := cfg.Provider(name)
+	if !ok {
+		return "", ErrOIDCProviderNotFound
+	}
+	provider, err := s.discover(ctx, p.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := newRandomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	request := repository.OAuthState{Provider: name, CodeVerifier: verifier, Nonce: nonce, UserID: userID}
+	if err := s.states.Save(ctx, hashToken(state), request, cfg.GetStateTTL()); err != nil {
+		return "", err
+	}
+	return oauth2Config(p, provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Login 使用授权回调中的 code 和 state 登录
+// 身份未关联时，提供方允许注册则自动创建账号，邮箱已被本站账号使用时需登录后手动关联
+func (s *OIDCService) Login(ctx context.Context, name, code, state string) (*LoginResult, error) {
+	p, claims, err := s.exchange(ctx, name, code, state, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	identity, err := s.identities.FindBySubject(ctx, name, claims.Subject)
+	switch {
+	case err == nil:
+		if user, err = s.users.GetUserByID(ctx, identity.UserID); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, repository.ErrNotFound):
+		if !p.AllowSignup {
+			return nil, ErrIdentityNotLinked
+		}
+		if user, err = s.signup(ctx, name, claims); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if err := s.users.checkLoginStatus(ctx, user); err != nil {
+		return nil, err
+	}
+	return s.users.finishLogin(ctx, user)
+}
+
+// Link 为已登录用户关联第三方身份，state 必须由该用户通过 AuthURL 创建
+func (s *OIDCService) Link(ctx context.Context, userID uint, name, code, state string) (*models.UserIdentity, error) {
+	_, claims, err := s.exchange(ctx, name, code, state, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &models.UserIdentity{UserID: userID, Provider: name, Subject: claims.Subject, Email: claims.Email}
+	if err := s.identities.Create(ctx, identity); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, s.linkConflict(ctx, userID, name, claims.Subject)
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+// linkConflict 区分关联冲突的原因
+func (s *OIDCService) linkConflict(ctx context.Context, userID uint, name, subject string) error {
+	existing, err := s.identities.FindBySubject(ctx, name, subject)
+	if err == nil && existing.UserID != userID {
+		return ErrIdentityTaken
+	}
+	return ErrIdentityProviderTaken
+}
+
+// ListIdentities 用户关联的第三方身份
+func (s *OIDCService) ListIdentities(ctx context.Context, userID uint) ([]models.UserIdentity, error) {
+	identities, err := s.identities.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if identities == nil {
+		identities = []models.UserIdentity{}
+	}
+	return identities, nil
+}
+
+// Unlink 解除关联，账号没有密码时不能解除最后一个第三方身份
+func (s *OIDCService) Unlink(ctx context.Context, userID uint, name string) error {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" {
+		identities, err := s.identities.ListByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) == 1 && identities[0].Provider == name {
+			return ErrLastLoginMethod
+		}
+	}
+
+	if err := s.identities.Delete(ctx, userID, name); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrIdentityNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// exchange 校验 state，用 code 和 PKCE 校验码换取并验证 ID Token
+// state 只能使用一次，且必须属于该提供方和该用户（登录时为 0）
+func (s *OIDCService) exchange(ctx context.Context, name, code, state string, userID uint) (*config.OIDCProviderConfig, *oidcClaims, error) {
+	request, err := s.states.Consume(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidOAuthState
+		}
+		return nil, nil, err
+	}
+	if request.Provider != name || request.UserID != userID {
+		return nil, nil, ErrInvalidOAuthState
+	}
+
+	p, ok := config.Get().OIDC.Provider(name)
+	if !ok {
+		return nil, nil, ErrOIDCProviderNotFound
+	}
+	provider, err := s.discover(ctx, p.Issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, s.client)
+	token, err := oauth2Config(p, provider).Exchange(ctx, code, oauth2.VerifierOption(request.CodeVerifier))
+	if err != nil {
+		// 令牌端点拒绝（code 无效、已使用或校验码不匹配）视为验证失败，其余为网络等错误
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return nil, nil, ErrOIDCAuthFailed.WithCause(err)
+		}
+		return nil, nil, ErrOIDCUnavailable.WithCause(err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, nil, ErrOIDCAuthFailed.WithCause(errors.New("令牌响应中没有 id_token"))
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: p.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, nil, ErrOIDCAuthFailed.WithCause(err)
+	}
+	if idToken.Nonce != request.Nonce {
+		return nil, nil, ErrOIDCAuthFailed.WithCause(errors.New("id_token 的 nonce 不匹配"))
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, nil, ErrOIDCAuthFailed.WithCause(err)
+	}
+	claims.Email = strings.TrimSpace(claims.Email)
+	return p, &claims, nil
+}
+
+// discover 获取签发者的发现文档，成功后缓存，失败时下次请求重试
+// 请求在锁外进行，同一签发者的并发请求合并为一次，不可用的签发者不会阻塞其他提供方
+func (s *OIDCService) discover(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	s.mu.Lock()
+	provider, ok := s.providers[issuer]
+	s.mu.Unlock()
+	if ok {
+		return provider, nil
+	}
+
+	v, err, _ := s.discovery.Do(issuer, func() (interface{}, error) {
+		// 结果由等待中的请求共享，不随发起请求的客户端断开而取消，超时由 HTTP 客户端控制
+		provider, err := oidc.NewProvider(oidc.ClientContext(context.WithoutCancel(ctx), s.client), issuer)
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.providers[issuer] = provider
+		s.mu.Unlock()
+		return provider, nil
+	})
+	if err != nil {
+		logger.WithContext(ctx).Warn("获取 OIDC 发现文档失败", zap.String("issuer", issuer), zap.Error(err))
+		return nil, ErrOIDCUnavailable.WithCause(err)
+	}
+	return v.(*oidc.Provider), nil
+}
+
+// signup 为未关联的第三方身份注册账号，用户名取自 preferred_username 或邮箱，重复时追加随机后缀
+// 账号没有密码，只能通过第三方登录，或通过找回密码设置密码
+func (s *OIDCService) signup(ctx context.Context, name string, claims *oidcClaims) (*models.User, error) {
+	// 只使用提供方已验证的邮箱，邮箱已注册时不自动关联，避免冒用他人邮箱接管账号
+	email := ""
+	if claims.EmailVerified && claims.Email != "" {
+		exists, err := s.users.users.ExistsByEmail(ctx, claims.Email)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrIdentityEmailTaken
+		}
+		email = claims.Email
+	}
+
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: username,
+		Email:    email,
+		Nickname: truncate(claims.Name, 50),
+		Role:     models.RoleUser,
+		Status:   models.UserStatusActive,
+	}
+	if email != "" {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if len(claims.Picture) <= 255 {
+		user.Avatar = claims.Picture
+	}
+
+	// 用户和身份在同一事务中创建，并发回调时唯一索引兜底
+	err = s.uow.Do(ctx, func(ctx context.Context, tx *repository.Repositories) error {
+		if err := tx.Users.Create(ctx, user); err != nil {
+			return err
+		}
+		return tx.Identities.Create(ctx, &models.UserIdentity{UserID: user.ID, Provider: name, Subject: claims.Subject, Email: claims.Email})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrIdentityTaken
+		}
+		return nil, err
+	}
+
+	metrics.UserRegistrations.Inc()
+	return user, nil
+}
+
+// availableUsername 根据声明生成未被使用的用户名
+func (s *OIDCService) availableUsername(ctx context.Context, claims *oidcClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = sanitizeUsername(base)
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		exists, err := s.users.users.ExistsByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		candidate = base + "_" + hex.EncodeToString(suffix)
+	}
+	return "", ErrUsernameTaken
+}
+
+// sanitizeUsername 将任意字符串转换为合法的用户名，不合法的字符替换为下划线
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case b.Len() > 0:
+			b.WriteByte('_')
+		}
+	}
+
+	name := strings.TrimLeft(strings.Trim(b.String(), "_"), "0123456789_")
+	if len(name) > 30 {
+		name = strings.TrimRight(name[:30], "_")
+	}
+	if len(name) < 3 || !validation.UsernamePattern.MatchString(name) {
+		return "user"
+	}
+	return name
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+// oauth2Config 提供方的 OAuth2 客户端配置，每次按当前配置创建以支持热更新
+func oauth2Config(p *config.OIDCProviderConfig, provider *oidc.Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  p.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.GetScopes(),
+	}
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/internal/repository/memory"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+const (
+	testClientID = "blog"
+	testKeyID    = "test-key"
+)
+
+// mockIdP 模拟的身份提供方，提供发现文档、签名公钥和令牌端点
+type mockIdP struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]idpGrant // 授权码 -> 授权信息
+
+	discoveries atomic.Int32 // 发现文档的请求次数
+}
+
+// idpGrant 授权码对应的授权请求和 ID Token 声明
+type idpGrant struct {
+	challenge string // PKCE code_challenge
+	nonce     string
+	claims    jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &mockIdP{key: key, grants: make(map[string]idpGrant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.handleDiscovery)
+	mux.HandleFunc("/jwks", idp.handleJWKS)
+	mux.HandleFunc("/token", idp.handleToken)
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *mockIdP) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	idp.discoveries.Add(1)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                idp.URL,
+		"authorization_endpoint":                idp.URL + "/authorize",
+		"token_endpoint":                        idp.URL + "/token",
+		"jwks_uri":                              idp.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (idp *mockIdP) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	enc := base64.RawURLEncoding
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   enc.EncodeToString(idp.key.N.Bytes()),
+			"e":   enc.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}},
+	})
+}
+
+// handleToken 校验授权码和 PKCE 校验码，返回签名的 ID Token，授权码只能使用一次
+func (idp *mockIdP) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	idp.mu.Lock()
+	grant, ok := idp.grants[r.PostForm.Get("code")]
+	delete(idp.grants, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   idp.URL,
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": grant.nonce,
+	}
+	for k, v := range grant.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	idToken, err := token.SignedString(idp.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// authorize 模拟用户在身份提供方同意授权，返回回调中的 code 和 state
+// claims 中的 aud、nonce 等声明会覆盖默认值
+func (idp *mockIdP) authorize(t *testing.T, authURL string, claims jwt.MapClaims) (code, state string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != testClientID || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("unexpected authorization request %s", authURL)
+	}
+
+	code = rand.Text()
+	idp.mu.Lock()
+	idp.grants[code] = idpGrant{challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), claims: claims}
+	idp.mu.Unlock()
+	return code, q.Get("state")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newOIDCService 基于内存仓储创建第三方登录服务，配置名为 mock 的提供方
+func newOIDCService(t *testing.T, idp *mockIdP, allowSignup bool) (*OIDCService, *UserService, *repository.Repositories) {
+	t.Helper()
+	setConfig(t, func(c *config.Config) {
+		c.OIDC.Providers = []config.OIDCProviderConfig{{
+			Name:         "mock",
+			Issuer:       idp.URL,
+			ClientID:     testClientID,
+			ClientSecret: "client-secret",
+			RedirectURL:  "http://localhost/auth/callback",
+			AllowSignup:  allowSignup,
+		}}
+	})
+
+	repos := memory.NewRepositories()
+	users := NewUserService(repos.Users, repos.RecoveryCodes, repos.PasswordResets, repos.LoginAttempts)
+	return NewOIDCService(users, repos.Identities, repos.OAuthStates, repos.UnitOfWork), users, repos
+}
+
+// oidcCallback 发起授权请求并模拟身份提供方回调
+func oidcCallback(t *testing.T, s *OIDCService, idp *mockIdP, userID uint, claims jwt.MapClaims) (code, state string) {
+	t.Helper()
+	authURL, err := s.AuthURL(context.Background(), "mock", userID)
+	if err != nil {
+		t.Fatalf("AuthURL() error = %v", err)
+	}
+	return idp.authorize(t, authURL, claims)
+}
+
+func TestOIDCLoginSignup(t *testing.T) {
+	idp := newMockIdP(t)
+	s, users, _ := newOIDCService(t, idp, true)
+	ctx := context.Background()
+	claims := jwt.MapClaims{"sub": "s-1", "email": "alice@example.com", "email_verified": true, "preferred_username": "alice", "name": "Alice"}
+
+	code, state := oidcCallback(t, s, idp, 0, claims)
+	result, err := s.Login(ctx, "mock", code, state)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if result.Token == "" {
+		t.Fatalf("Login() = %+v, want tokens", result)
+	}
+
+	user, err := users.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "alice@example.com" || !user.EmailVerified() || user.Password != "" || user.Nickname != "Alice" {
+		t.Errorf("signed up user = %+v", user)
+	}
+
+	// 再次登录使用已关联的账号
+	code, state = oidcCallback(t, s, idp, 0, claims)
+	if _, err := s.Login(ctx, "mock", code, state); err != nil {
+		t.Fatalf("second Login() error = %v", err)
+	}
+	if _, total, _ := users.SearchUsers(ctx, repository.UserFilter{}, 1, 10); total != 1 {
+		t.Errorf("user count = %d, want 1", total)
+	}
+
+	// 发现文档只请求一次
+	if n := idp.discoveries.Load(); n != 1 {
+		t.Errorf("discovery requests = %d, want 1", n)
+	}
+}
+
+func TestOIDCLoginRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+	}{
+		{"nonce mismatch", jwt.MapClaims{"sub": "s-1", "nonce": "other-nonce"}},
+		{"wrong audience", jwt.MapClaims{"sub": "s-1", "aud": "other-client"}},
+		{"wrong issuer", jwt.MapClaims{"sub": "s-1", "iss": "https://evil.example.com"}},
+		{"expired", jwt.MapClaims{"sub": "s-1", "exp": time.Now().Add(-time.Hour).Unix()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			s, _, _ := newOIDCService(t, idp, true)
+
+			code, state := oidcCallback(t, s, idp, 0, tt.claims)
+			if _, err := s.Login(context.Background(), "mock", code, state); !errors.Is(err, ErrOIDCAuthFailed) {
+				t.Errorf("Login() error = %v, want %v", err, ErrOIDCAuthFailed)
+			}
+		})
+	}
+}
+
+func TestOIDCPKCE(t *testing.T) {
+	idp := newMockIdP(t)
+	s, _, _ := newOIDCService(t, idp, true)
+
+	// 身份提供方记录的 code_challenge 与服务保存的校验码不对应
+	code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-1"})
+	idp.mu.Lock()
+	grant := idp.grants[code]
+	grant.challenge = base64.RawURLEncoding.EncodeToString(make([]byte, sha256.Size))
+	idp.grants[code] = grant
+	idp.mu.Unlock()
+
+	if _, err := s.Login(context.Background(), "mock", code, state); !errors.Is(err, ErrOIDCAuthFailed) {
+		t.Errorf("Login() with mismatched verifier error = %v, want %v", err, ErrOIDCAuthFailed)
+	}
+}
+
+func TestOIDCState(t *testing.T) {
+	idp := newMockIdP(t)
+	s, users, _ := newOIDCService(t, idp, true)
+	ctx := context.Background()
+	bob := mustRegister(t, users, "bob", "password1", "")
+
+	code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-1"})
+	if _, err := s.Login(ctx, "mock", code, state); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	// state 只能使用一次
+	code, _ = oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-1"})
+	if _, err := s.Login(ctx, "mock", code, state); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Login() with used state error = %v, want %v", err, ErrInvalidOAuthState)
+	}
+
+	// 关联请求的 state 不能用于登录，也不能被其他用户使用
+	code, state = oidcCallback(t, s, idp, bob, jwt.MapClaims{"sub": "s-2"})
+	if _, err := s.Login(ctx, "mock", code, state); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Login() with link state error = %v, want %v", err, ErrInvalidOAuthState)
+	}
+	code, state = oidcCallback(t, s, idp, bob, jwt.MapClaims{"sub": "s-2"})
+	if _, err := s.Link(ctx, bob+1, "mock", code, state); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Link() by another user error = %v, want %v", err, ErrInvalidOAuthState)
+	}
+
+	if _, err := s.Login(ctx, "mock", "code", "unknown-state"); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("Login() with unknown state error = %v, want %v", err, ErrInvalidOAuthState)
+	}
+}
+
+func TestOIDCSignup(t *testing.T) {
+	idp := newMockIdP(t)
+	ctx := context.Background()
+
+	t.Run("email taken", func(t *testing.T) {
+		s, users, _ := newOIDCService(t, idp, true)
+		mustRegister(t, users, "alice", "password1", "alice@example.com")
+
+		code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-1", "email": "alice@example.com", "email_verified": true})
+		if _, err := s.Login(ctx, "mock", code, state); !errors.Is(err, ErrIdentityEmailTaken) {
+			t.Errorf("Login() error = %v, want %v", err, ErrIdentityEmailTaken)
+		}
+	})
+
+	t.Run("unverified email ignored", func(t *testing.T) {
+		s, users, _ := newOIDCService(t, idp, true)
+		mustRegister(t, users, "alice", "password1", "alice@example.com")
+
+		code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-1", "email": "alice@example.com", "email_verified": false})
+		if _, err := s.Login(ctx, "mock", code, state); err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		// 未验证的邮箱不使用，用户名与已有账号重复时追加后缀
+		found, _, err := users.SearchUsers(ctx, repository.UserFilter{}, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 2 || found[0].Email != "" || found[0].Username == "alice" {
+			t.Errorf("signed up user = %+v, want a new user without email", found[0])
+		}
+	})
+
+	t.Run("signup disabled", func(t *testing.T) {
+		s, _, _ := newOIDCService(t, idp, false)
+		code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-1"})
+		if _, err := s.Login(ctx, "mock", code, state); !errors.Is(err, ErrIdentityNotLinked) {
+			t.Errorf("Login() error = %v, want %v", err, ErrIdentityNotLinked)
+		}
+	})
+}
+
+func TestOIDCLinkUnlink(t *testing.T) {
+	idp := newMockIdP(t)
+	s, users, _ := newOIDCService(t, idp, true)
+	ctx := context.Background()
+	bob := mustRegister(t, users, "bob", "password1", "")
+	carol := mustRegister(t, users, "carol", "password1", "")
+
+	link := func(userID uint, subject string) error {
+		code, state := oidcCallback(t, s, idp, userID, jwt.MapClaims{"sub": subject})
+		_, err := s.Link(ctx, userID, "mock", code, state)
+		return err
+	}
+
+	if err := link(bob, "s-bob"); err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	if err := link(carol, "s-bob"); !errors.Is(err, ErrIdentityTaken) {
+		t.Errorf("Link() identity of another user error = %v, want %v", err, ErrIdentityTaken)
+	}
+	if err := link(bob, "s-other"); !errors.Is(err, ErrIdentityProviderTaken) {
+		t.Errorf("Link() second identity of the provider error = %v, want %v", err, ErrIdentityProviderTaken)
+	}
+
+	// 关联后可以通过第三方登录
+	code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-bob"})
+	if _, err := s.Login(ctx, "mock", code, state); err != nil {
+		t.Fatalf("Login() with linked identity error = %v", err)
+	}
+
+	if err := s.Unlink(ctx, bob, "mock"); err != nil {
+		t.Fatalf("Unlink() error = %v", err)
+	}
+	if err := s.Unlink(ctx, bob, "mock"); !errors.Is(err, ErrIdentityNotFound) {
+		t.Errorf("Unlink() twice error = %v, want %v", err, ErrIdentityNotFound)
+	}
+
+	// 没有密码的账号不能解除唯一的登录方式
+	code, state = oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-dave", "preferred_username": "dave"})
+	if _, err := s.Login(ctx, "mock", code, state); err != nil {
+		t.Fatal(err)
+	}
+	dave, err := users.GetUserByUsername(ctx, "dave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Unlink(ctx, dave.ID, "mock"); !errors.Is(err, ErrLastLoginMethod) {
+		t.Errorf("Unlink() last login method error = %v, want %v", err, ErrLastLoginMethod)
+	}
+}
+
+func TestOIDCLoginChecksAccount(t *testing.T) {
+	idp := newMockIdP(t)
+	s, users, repos := newOIDCService(t, idp, true)
+	ctx := context.Background()
+	bob := mustRegister(t, users, "bob", "password1", "")
+	if err := repos.Identities.Create(ctx, &models.UserIdentity{UserID: bob, Provider: "mock", Subject: "s-bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已启用两步验证时第三方登录同样需要验证动态码
+	mustEnableTwoFactor(t, users, bob)
+	code, state := oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-bob"})
+	result, err := s.Login(ctx, "mock", code, state)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if result.MFAToken == "" || result.Token != "" {
+		t.Errorf("Login() = %+v, want only an MFA token", result)
+	}
+
+	if err := users.BanUser(ctx, bob, "spam", nil); err != nil {
+		t.Fatal(err)
+	}
+	code, state = oidcCallback(t, s, idp, 0, jwt.MapClaims{"sub": "s-bob"})
+	if _, err := s.Login(ctx, "mock", code, state); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("Login() for banned user error = %v, want %v", err, ErrUserDisabled)
+	}
+}
+
+func TestOIDCDiscoveryDoesNotBlockOtherIssuers(t *testing.T) {
+	idp := newMockIdP(t)
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+
+	s, _, _ := newOIDCService(t, idp, true)
+	setConfig(t, func(c *config.Config) {
+		c.OIDC.Providers = []config.OIDCProviderConfig{
+			{Name: "mock", Issuer: idp.URL, ClientID: testClientID},
+			{Name: "slow", Issuer: slow.URL, ClientID: testClientID},
+		}
+	})
+
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := s.AuthURL(context.Background(), "slow", 0)
+		slowDone <- err
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.AuthURL(context.Background(), "mock", 0)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("AuthURL() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("AuthURL() blocked by another issuer's discovery")
+	}
+
+	select {
+	case err := <-slowDone:
+		t.Fatalf("slow AuthURL() returned early: %v", err)
+	default:
+	}
+}
+
+func TestOIDCDiscoveryUnavailable(t *testing.T) {
+	idp := newMockIdP(t)
+	s, _, _ := newOIDCService(t, idp, true)
+	idp.Close()
+
+	if _, err := s.AuthURL(context.Background(), "mock", 0); !errors.Is(err, ErrOIDCUnavailable) {
+		t.Errorf("AuthURL() error = %v, want %v", err, ErrOIDCUnavailable)
+	}
+	if _, err := s.AuthURL(context.Background(), "unknown", 0); !errors.Is(err, ErrOIDCProviderNotFound) {
+		t.Errorf("AuthURL() for unknown provider error = %v, want %v", err, ErrOIDCProviderNotFound)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	"github.com/xiaoxin/blog-backend/pkg/mail"
+)
+
+// passwordResetCooldown 同一用户两封重置密码邮件的最小间隔
+const passwordResetCooldown = time.Minute
+
+// ForgotPassword 向邮箱发送重置密码链接
+// 无论邮箱是否注册都返回成功，邮件在后台发送，响应内容和耗时都不会暴露邮箱是否存在
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	if s.resets == nil {
+		return ErrPasswordResetUnavailable
+	}
+
+	locale := i18n.FromContext(ctx)
+	s.background.run(ctx, "password_reset", func(ctx context.Context) error {
+		return s.sendPasswordReset(ctx, email, locale)
+	})
+	return nil
+}
+
+// ResetPasswordByToken 使用重置链接中的令牌设置新密码
+// 令牌只能使用一次，重置成功后用户已签发的全部令牌失效
+func (s *UserService) ResetPasswordByToken(ctx context.Context, token, newPassword string) error {
+	if s.resets == nil {
+		return ErrPasswordResetUnavailable
+	}
+
+	id, err := s.resets.Consume(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	password := string(hashedPassword)
+	update := repository.UserUpdate{Password: &password}
+	// 能收到重置邮件说明邮箱属于该用户，顺便完成验证
+	if !user.EmailVerified() {
+		now := time.Now()
+		update.EmailVerifiedAt = &now
+	}
+	if err := s.update(ctx, id, update); err != nil {
+		return err
+	}
+
+	return s.ForceLogout(ctx, id)
+}
+
+// sendPasswordReset 生成重置令牌并发送邮件，邮箱未注册或发送过于频繁时直接忽略
+func (s *UserService) sendPasswordReset(ctx context.Context, email, locale string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status != models.UserStatusActive || !s.resetCooldown.allow(user.ID, time.Now()) {
+		return nil
+	}
+
+	cfg := config.Get().PasswordReset
+	ttl := cfg.GetTokenTTL()
+
+	token, err := newRandomToken()
+	if err != nil {
+		return err
+	}
+	if err := s.resets.Save(ctx, user.ID, hashToken(token), ttl); err != nil {
+		return err
+	}
+
+	link, err := url.Parse(cfg.ResetURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	if user.Locale != "" {
+		locale = user.Locale
+	}
+	name := user.Nickname
+	if name == "" {
+		name = user.Username
+	}
+
+	return mail.Send(ctx, &mail.Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "password_reset_subject"),
+		Text:    i18n.T(locale, "password_reset_body", name, int(ttl.Minutes()), link.String()),
+	})
+}
+
+// newRandomToken 生成 256 位随机令牌，用于重置密码链接和第三方登录的 state、nonce
+func newRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken 令牌的 SHA-256 哈希，存储中只保存哈希，泄露后也不能直接使用
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+)
+
+// resetTokenPattern 从重置邮件的链接中提取令牌
+var resetTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// requestPasswordReset 申请重置密码并返回邮件中的令牌
+func requestPasswordReset(t *testing.T, s *UserService, mailer *fakeMailer, email string) string {
+	t.Helper()
+	if err := s.ForgotPassword(context.Background(), email); err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+	if !mailer.wait(time.Second) {
+		t.Fatal("ForgotPassword() sent no mail")
+	}
+	msgs := mailer.messages()
+	match := resetTokenPattern.FindStringSubmatch(msgs[len(msgs)-1].Text)
+	if match == nil {
+		t.Fatalf("reset mail has no token: %q", msgs[len(msgs)-1].Text)
+	}
+	return match[1]
+}
+
+func TestResetPasswordByToken(t *testing.T) {
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+
+	user, err := s.Register(ctx, "alice", "password1", "alice@example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailer.drain() // 丢弃验证邮件的通知
+
+	token := requestPasswordReset(t, s, mailer, "alice@example.com")
+	if msgs := mailer.messages(); msgs[len(msgs)-1].To != "alice@example.com" {
+		t.Errorf("reset mail sent to %q", msgs[len(msgs)-1].To)
+	}
+
+	if err := s.ResetPasswordByToken(ctx, token, "password2"); err != nil {
+		t.Fatalf("ResetPasswordByToken() error = %v", err)
+	}
+	// 令牌只能使用一次
+	if err := s.ResetPasswordByToken(ctx, token, "password3"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ResetPasswordByToken() reused error = %v, want %v", err, ErrInvalidResetToken)
+	}
+
+	if _, err := s.Login(ctx, "alice", "password1", "127.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() with old password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := s.Login(ctx, "alice", "password2", "127.0.0.1"); err != nil {
+		t.Errorf("Login() with new password error = %v", err)
+	}
+
+	// 重置后已签发的令牌失效，邮箱视为已验证
+	updated, err := s.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.TokenVersion != user.TokenVersion+1 {
+		t.Errorf("TokenVersion = %d, want %d", updated.TokenVersion, user.TokenVersion+1)
+	}
+	if _, err := s.ValidateSession(ctx, user.ID, user.TokenVersion); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateSession() with old version error = %v, want %v", err, ErrTokenRevoked)
+	}
+	if !updated.EmailVerified() {
+		t.Error("email not verified after reset")
+	}
+}
+
+func TestResetPasswordByTokenInvalid(t *testing.T) {
+	s, _ := newUserService(t)
+	for _, token := range []string{"", "not-a-token"} {
+		if err := s.ResetPasswordByToken(context.Background(), token, "password2"); !errors.Is(err, ErrInvalidResetToken) {
+			t.Errorf("ResetPasswordByToken(%q) error = %v, want %v", token, err, ErrInvalidResetToken)
+		}
+	}
+}
+
+func TestNewerResetTokenReplacesOlder(t *testing.T) {
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	if _, err := s.CreateUser(ctx, "alice", "password1", "alice@example.com", "", models.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+
+	older := requestPasswordReset(t, s, mailer, "alice@example.com")
+	s.resetCooldown = newCooldown(0)
+	newer := requestPasswordReset(t, s, mailer, "alice@example.com")
+
+	if err := s.ResetPasswordByToken(ctx, older, "password2"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("ResetPasswordByToken() with older token error = %v, want %v", err, ErrInvalidResetToken)
+	}
+	if err := s.ResetPasswordByToken(ctx, newer, "password2"); err != nil {
+		t.Errorf("ResetPasswordByToken() with newer token error = %v", err)
+	}
+}
+
+func TestForgotPasswordSendsNothing(t *testing.T) {
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "alice@example.com")
+	mailer.drain()
+	if err := s.BanUser(ctx, id, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := len(mailer.messages())
+
+	// 未注册的邮箱和被封禁的用户同样返回成功，但不发送邮件
+	for _, email := range []string{"nobody@example.com", "alice@example.com"} {
+		if err := s.ForgotPassword(ctx, email); err != nil {
+			t.Errorf("ForgotPassword(%q) error = %v", email, err)
+		}
+	}
+	if mailer.wait(100 * time.Millisecond) {
+		t.Errorf("ForgotPassword() sent %v", mailer.messages()[sent:])
+	}
+}
+
+func TestForgotPasswordCooldown(t *testing.T) {
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	if _, err := s.CreateUser(context.Background(), "alice", "password1", "alice@example.com", "", models.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+
+	requestPasswordReset(t, s, mailer, "alice@example.com")
+	if err := s.ForgotPassword(context.Background(), "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if mailer.wait(100 * time.Millisecond) {
+		t.Error("ForgotPassword() sent a second mail within the cooldown")
+	}
+}
+
+func TestPasswordResetUnavailable(t *testing.T) {
+	_, repos := newUserService(t)
+	s := NewUserService(repos.Users, nil, nil, nil)
+	if err := s.ForgotPassword(context.Background(), "alice@example.com"); !errors.Is(err, ErrPasswordResetUnavailable) {
+		t.Errorf("ForgotPassword() error = %v, want %v", err, ErrPasswordResetUnavailable)
+	}
+}
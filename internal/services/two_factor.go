@@ -0,0 +1,391 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"image/png"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
+)
+
+const (
+	// mfaTokenTTL 登录时两步验证挑战令牌的有效期
+	mfaTokenTTL = 5 * time.Minute
+	// mfaMaxAttempts 两步验证在挑战令牌有效期内允许的失败次数
+	mfaMaxAttempts = 5
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+)
+
+// totpOpts RFC 6238 默认参数：30 秒时间步、6 位数字、SHA1，允许前后各一个时间步的时钟偏差
+var totpOpts = totp.ValidateOpts{Period: 30, Skew: 1, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+// TwoFactorSetup 两步验证绑定信息
+type TwoFactorSetup struct {
+	Secret string // Base32 编码的密钥，无法扫码时手动输入
+	URL    string // otpauth:// 地址
+	QRCode []byte // otpauth 地址的二维码，PNG 格式
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool
+	EnabledAt              *time.Time
+	RecoveryCodesRemaining int64
+	Required               bool // 当前角色是否必须启用
+}
+
+// SetupTwoFactor 生成新的两步验证密钥，需调用 EnableTwoFactor 确认后才生效
+// 重复调用会替换尚未确认的密钥
+func (s *UserService) SetupTwoFactor(ctx context.Context, id uint) (*TwoFactorSetup, error) {
+	if s.codes == nil {
+		return nil, ErrTwoFactorUnavailable
+	}
+
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      twoFactorIssuer(),
+		AccountName: user.Username,
+		Period:      uint(totpOpts.Period),
+		Digits:      totpOpts.Digits,
+		Algorithm:   totpOpts.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return nil, err
+	}
+	var qr bytes.Buffer
+	if err := png.Encode(&qr, img); err != nil {
+		return nil, err
+	}
+
+	secret := key.Secret()
+	if err := s.update(ctx, id, repository.UserUpdate{TOTPSecret: &secret}); err != nil {
+		return nil, err
+	}
+	return &TwoFactorSetup{Secret: secret, URL: key.URL(), QRCode: qr.Bytes()}, nil
+}
+
+// EnableTwoFactor 使用验证器应用生成的动态码确认绑定并启用两步验证，返回恢复码
+// 恢复码只在此时返回一次，存储中只保存哈希；启用前签发的令牌未经过两步验证，全部失效
+func (s *UserService) EnableTwoFactor(ctx context.Context, id uint, code string) ([]string, error) {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" || s.codes == nil {
+		return nil, ErrTwoFactorNotSetup
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.update(ctx, id, repository.UserUpdate{TOTPEnabledAt: &now}); err != nil {
+		return nil, err
+	}
+	if err := s.ForceLogout(ctx, id); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需要密码和动态码（或恢复码）
+func (s *UserService) DisableTwoFactor(ctx context.Context, id uint, password, code string) error {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrWrongPassword
+	}
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		return err
+	}
+	return s.ResetTwoFactor(ctx, id)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，之前的恢复码全部失效，需要动态码
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, id uint, code string) ([]string, error) {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, id)
+}
+
+// GetTwoFactorStatus 获取两步验证状态
+func (s *UserService) GetTwoFactorStatus(ctx context.Context, id uint) (*TwoFactorStatus, error) {
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{
+		Enabled:   user.TwoFactorEnabled(),
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  twoFactorRequired(user.Role),
+	}
+	if status.Enabled && s.codes != nil {
+		if status.RecoveryCodesRemaining, err = s.codes.CountUnused(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// ResetTwoFactor 清除两步验证密钥和恢复码，用于用户关闭或管理员为丢失设备的用户重置
+func (s *UserService) ResetTwoFactor(ctx context.Context, id uint) error {
+	secret := ""
+	if err := s.update(ctx, id, repository.UserUpdate{TOTPSecret: &secret, TOTPEnabledAt: &time.Time{}}); err != nil {
+		return err
+	}
+
+	if s.codes == nil {
+		return nil
+	}
+	return s.codes.DeleteByUser(ctx, id)
+}
+
+// CheckTwoFactorEnabled 检查用户是否已启用两步验证，未启用时返回 ErrTwoFactorRequired
+func (s *UserService) CheckTwoFactorEnabled(ctx context.Context, id uint) error {
+	user, err := s.currentUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorRequired
+	}
+	return nil
+}
+
+// VerifyLoginTwoFactor 登录第二步，验证挑战令牌和动态码（或恢复码）后签发令牌
+// 挑战令牌绑定登录时的令牌版本，期间被强制下线或重置密码时失效
+func (s *UserService) VerifyLoginTwoFactor(ctx context.Context, mfaToken, code string) (*LoginResult, error) {
+	claims, err := pkgjwt.ParseActionToken(pkgjwt.PurposeMFALogin, mfaToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.users.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+	if claims.Value != strconv.Itoa(user.TokenVersion) || !user.TwoFactorEnabled() {
+		return nil, ErrInvalidMFAToken
+	}
+	if user.IsBanned(time.Now()) {
+		metrics.UserLogins.WithLabelValues("disabled").Inc()
+		return nil, banError(user)
+	}
+
+	// 动态码只有六位，挑战令牌有效期内限制失败次数
+	key := "mfa:" + strconv.FormatUint(uint64(user.ID), 10)
+	if s.attempts != nil {
+		failures, err := s.attempts.Failures(ctx, key)
+		if err != nil {
+			logger.WithContext(ctx).Warn("查询两步验证失败次数失败", zap.Error(err))
+		}
+		if failures >= mfaMaxAttempts {
+			metrics.UserLogins.WithLabelValues("locked").Inc()
+			return nil, ErrInvalidTwoFactorCode
+		}
+	}
+
+	if err := s.verifySecondFactor(ctx, user, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) && s.attempts != nil {
+			if _, err := s.attempts.AddFailure(ctx, key, mfaTokenTTL); err != nil {
+				logger.WithContext(ctx).Warn("记录两步验证失败次数失败", zap.Error(err))
+			}
+		}
+		metrics.UserLogins.WithLabelValues("failure").Inc()
+		return nil, err
+	}
+
+	if s.attempts != nil {
+		if err := s.attempts.ClearFailures(ctx, key); err != nil {
+			logger.WithContext(ctx).Warn("清除两步验证失败次数失败", zap.Error(err))
+		}
+	}
+	return s.issueTokens(ctx, user)
+}
+
+// verifySecondFactor 验证六位动态码或恢复码，恢复码使用后失效
+func (s *UserService) verifySecondFactor(ctx context.Context, user *models.User, code string) error {
+	code = normalizeCode(code)
+	if len(code) == int(totpOpts.Digits) && isDigits(code) {
+		return s.verifyTOTP(ctx, user, code)
+	}
+	if s.codes == nil || code == "" {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.codes.Use(ctx, user.ID, hashRecoveryCode(code)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	logger.WithContext(ctx).Info("使用恢复码通过两步验证", zap.Uint("user_id", user.ID))
+	return nil
+}
+
+// verifyTOTP 验证动态码，同一时间步的动态码只能使用一次
+func (s *UserService) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	if user.TOTPSecret == "" {
+		return ErrInvalidTwoFactorCode
+	}
+
+	step, ok, err := matchTOTP(user.TOTPSecret, normalizeCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	unused, err := s.users.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !unused {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// matchTOTP 在 now 前后允许的时钟偏差内查找动态码对应的时间步
+func matchTOTP(secret, code string, now time.Time) (int64, bool, error) {
+	if len(code) != int(totpOpts.Digits) {
+		return 0, false, nil
+	}
+
+	period := int64(totpOpts.Period)
+	current := now.Unix() / period
+	for step := current - int64(totpOpts.Skew); step <= current+int64(totpOpts.Skew); step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*period, 0), totpOpts)
+		if err != nil {
+			return 0, false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// replaceRecoveryCodes 生成新的恢复码并替换已有的恢复码
+func (s *UserService) replaceRecoveryCodes(ctx context.Context, id uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		// 40 位随机数编码为 8 个字符，分两组显示，如 k7qm-2xfa
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = raw[:4] + "-" + raw[4:]
+		hashes[i] = hashRecoveryCode(raw)
+	}
+
+	if err := s.codes.Replace(ctx, id, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// twoFactorIssuer 验证器应用中显示的服务名称
+func twoFactorIssuer() string {
+	cfg := config.Get()
+	if cfg == nil {
+		return "blog"
+	}
+	if cfg.TwoFactor.Issuer != "" {
+		return cfg.TwoFactor.Issuer
+	}
+	return cfg.App.Name
+}
+
+// twoFactorRequired 判断角色是否必须启用两步验证
+func twoFactorRequired(role string) bool {
+	cfg := config.Get()
+	return cfg != nil && cfg.TwoFactor.RequireForAdmin && role == models.RoleAdmin
+}
+
+// normalizeCode 去掉空白和连字符，恢复码不区分大小写
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+// hashRecoveryCode 恢复码的 SHA-256 哈希，参数为去掉连字符后的恢复码
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// isDigits 判断字符串是否全部为数字
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
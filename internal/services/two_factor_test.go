@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
+)
+
+// rfc6238Secret RFC 6238 附录 B 中 SHA1 测试用的密钥 "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestMatchTOTPVectors(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 测试向量，取八位动态码的后六位
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step, ok, err := matchTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if err != nil || !ok || step != tt.unix/30 {
+			t.Errorf("matchTOTP(%q, T=%d) = %d, %v, %v, want step %d", tt.code, tt.unix, step, ok, err, tt.unix/30)
+		}
+	}
+}
+
+func TestMatchTOTPSkew(t *testing.T) {
+	// T=1111111109 位于时间步 37037036，动态码 081804
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   bool
+	}{
+		{"previous step", 30 * time.Second, true},
+		{"next step", -30 * time.Second, true},
+		{"two steps later", 60 * time.Second, false},
+		{"two steps earlier", -60 * time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := matchTOTP(rfc6238Secret, "081804", time.Unix(1111111109, 0).Add(tt.offset))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want || (ok && step != 37037036) {
+				t.Errorf("matchTOTP() = %d, %v, want %v", step, ok, tt.want)
+			}
+		})
+	}
+
+	for _, code := range []string{"", "08180", "0818040", "999999"} {
+		if _, ok, _ := matchTOTP(rfc6238Secret, code, time.Unix(1111111109, 0)); ok {
+			t.Errorf("matchTOTP(%q) matched", code)
+		}
+	}
+}
+
+// totpCode 生成指定时间的动态码
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+	code, err := totp.GenerateCodeCustom(secret, at, totpOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// mustEnableTwoFactor 为用户启用两步验证，返回密钥和恢复码
+func mustEnableTwoFactor(t *testing.T, s *UserService, id uint) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	setup, err := s.SetupTwoFactor(ctx, id)
+	if err != nil {
+		t.Fatalf("SetupTwoFactor() error = %v", err)
+	}
+	codes, err := s.EnableTwoFactor(ctx, id, totpCode(t, setup.Secret, time.Now()))
+	if err != nil {
+		t.Fatalf("EnableTwoFactor() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("EnableTwoFactor() returned %d recovery codes", len(codes))
+	}
+	return setup.Secret, codes
+}
+
+// mustMFAToken 使用密码登录并返回两步验证挑战令牌
+func mustMFAToken(t *testing.T, s *UserService, username string) string {
+	t.Helper()
+	result, err := s.Login(context.Background(), username, "password1", "")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if result.MFAToken == "" || result.Token != "" {
+		t.Fatalf("Login() = %+v, want only an MFA token", result)
+	}
+	return result.MFAToken
+}
+
+func TestEnableTwoFactorRevokesSessions(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 启用前签发的令牌没有经过两步验证
+	mustEnableTwoFactor(t, s, id)
+	if _, err := s.ValidateSession(ctx, id, user.TokenVersion); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateSession() after EnableTwoFactor error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestVerifyLoginTwoFactor(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	secret, _ := mustEnableTwoFactor(t, s, id)
+	mfaToken := mustMFAToken(t, s, "alice")
+
+	// 启用时使用过的动态码不能再次使用
+	if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, totpCode(t, secret, time.Now())); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("VerifyLoginTwoFactor() with replayed code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	// 下一个时间步的动态码在允许的时钟偏差内
+	code := totpCode(t, secret, time.Now().Add(30*time.Second))
+	result, err := s.VerifyLoginTwoFactor(ctx, mfaToken, code[:3]+" "+code[3:])
+	if err != nil {
+		t.Fatalf("VerifyLoginTwoFactor() error = %v", err)
+	}
+	if result.Token == "" || result.RefreshToken == "" {
+		t.Errorf("VerifyLoginTwoFactor() = %+v, want tokens", result)
+	}
+
+	if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("VerifyLoginTwoFactor() with used code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+}
+
+func TestVerifyLoginTwoFactorRecoveryCode(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	_, codes := mustEnableTwoFactor(t, s, id)
+	mfaToken := mustMFAToken(t, s, "alice")
+
+	// 恢复码不区分大小写，可以省略连字符
+	code := codes[0]
+	if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, strings.ToUpper(strings.ReplaceAll(code, "-", ""))); err != nil {
+		t.Fatalf("VerifyLoginTwoFactor() with recovery code error = %v", err)
+	}
+	if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("VerifyLoginTwoFactor() with used recovery code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+	if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, " "+codes[1]+" "); err != nil {
+		t.Errorf("VerifyLoginTwoFactor() with another recovery code error = %v", err)
+	}
+
+	status, err := s.GetTwoFactorStatus(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount-2 {
+		t.Errorf("GetTwoFactorStatus() = %+v, want %d codes left", status, recoveryCodeCount-2)
+	}
+}
+
+func TestVerifyLoginTwoFactorRevokedToken(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	_, codes := mustEnableTwoFactor(t, s, id)
+	mfaToken := mustMFAToken(t, s, "alice")
+
+	// 挑战令牌有效期内被强制下线
+	if err := s.ForceLogout(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, codes[0]); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("VerifyLoginTwoFactor() after ForceLogout error = %v, want %v", err, ErrInvalidMFAToken)
+	}
+
+	// 访问令牌不能代替挑战令牌
+	token, err := pkgjwt.GenerateToken(id, "alice", models.RoleUser, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.VerifyLoginTwoFactor(ctx, token, codes[0]); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("VerifyLoginTwoFactor() with access token error = %v, want %v", err, ErrInvalidMFAToken)
+	}
+}
+
+func TestVerifyLoginTwoFactorLockout(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	secret, codes := mustEnableTwoFactor(t, s, id)
+	mfaToken := mustMFAToken(t, s, "alice")
+
+	// 使用不可能是动态码的错误值，避免与当前动态码偶然相同
+	for i := 0; i < mfaMaxAttempts; i++ {
+		if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, "zzzz-zzzz"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("attempt %d error = %v, want %v", i+1, err, ErrInvalidTwoFactorCode)
+		}
+	}
+
+	// 达到上限后正确的动态码和恢复码也被拒绝
+	for _, code := range []string{totpCode(t, secret, time.Now().Add(30*time.Second)), codes[0]} {
+		if _, err := s.VerifyLoginTwoFactor(ctx, mfaToken, code); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Errorf("VerifyLoginTwoFactor(%q) after lockout error = %v, want %v", code, err, ErrInvalidTwoFactorCode)
+		}
+	}
+	status, err := s.GetTwoFactorStatus(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.RecoveryCodesRemaining != recoveryCodeCount {
+		t.Errorf("recovery code consumed during lockout, %d left", status.RecoveryCodesRemaining)
+	}
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	_, codes := mustEnableTwoFactor(t, s, id)
+
+	if err := s.DisableTwoFactor(ctx, id, "wrong", codes[0]); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("DisableTwoFactor() with wrong password error = %v, want %v", err, ErrWrongPassword)
+	}
+	if err := s.DisableTwoFactor(ctx, id, "password1", "zzzz-zzzz"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("DisableTwoFactor() with wrong code error = %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+	if err := s.DisableTwoFactor(ctx, id, "password1", codes[0]); err != nil {
+		t.Fatalf("DisableTwoFactor() error = %v", err)
+	}
+
+	result, err := s.Login(ctx, "alice", "password1", "")
+	if err != nil || result.Token == "" {
+		t.Errorf("Login() after disabling = %+v, %v, want tokens", result, err)
+	}
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+	"github.com/xiaoxin/blog-backend/internal/repository"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+	pkgjwt "github.com/xiaoxin/blog-backend/pkg/jwt"
+)
+
+func TestRegister(t *testing.T) {
+	mailer := useMailer(t)
+	s, _ := newUserService(t)
+	ctx := context.Background()
+
+	user, err := s.Register(ctx, "alice", "password1", "alice@example.com", "Alice")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if user.Role != models.RoleUser || user.Status != models.UserStatusActive {
+		t.Errorf("Register() role = %q, status = %d", user.Role, user.Status)
+	}
+	if user.Password == "password1" {
+		t.Error("Register() stored the plain password")
+	}
+	if user.EmailVerifiedAt != nil {
+		t.Error("Register() marked the email as verified")
+	}
+	if msgs := mailer.messages(); len(msgs) != 1 || msgs[0].To != "alice@example.com" {
+		t.Errorf("Register() sent %v, want one verification mail", msgs)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		email    string
+		want     error
+	}{
+		{"duplicate username", "alice", "other@example.com", ErrUsernameTaken},
+		{"duplicate email", "bob", "alice@example.com", ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Register(ctx, tt.username, "password1", tt.email, "")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Register() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+
+	if _, err := s.CreateUser(ctx, "root", "password1", "", "", "owner"); !errors.Is(err, ErrInvalidRole) {
+		t.Errorf("CreateUser() with invalid role error = %v, want %v", err, ErrInvalidRole)
+	}
+
+	user, err := s.CreateUser(ctx, "admin", "password1", "admin@example.com", "", models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.Role != models.RoleAdmin || user.EmailVerifiedAt == nil {
+		t.Errorf("CreateUser() role = %q, verified = %v", user.Role, user.EmailVerifiedAt != nil)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+
+	result, err := s.Login(ctx, "alice", "password1", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if result.MFAToken != "" || result.RefreshToken == "" {
+		t.Errorf("Login() = %+v, want access and refresh tokens", result)
+	}
+	claims, err := pkgjwt.ParseToken(result.Token)
+	if err != nil {
+		t.Fatalf("ParseToken() error = %v", err)
+	}
+	if claims.UserID != id || claims.Username != "alice" {
+		t.Errorf("token claims = %+v", claims)
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"wrong password", "alice", "password2"},
+		{"unknown user", "nobody", "password1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Login(ctx, tt.username, tt.password, "127.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+				t.Errorf("Login() error = %v, want %v", err, ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestLoginBannedUser(t *testing.T) {
+	s, repos := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := s.BanUser(ctx, id, "spam", &until); err != nil {
+		t.Fatalf("BanUser() error = %v", err)
+	}
+
+	// 密码错误时不透露封禁状态
+	if _, err := s.Login(ctx, "alice", "password2", "127.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() with wrong password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	_, err := s.Login(ctx, "alice", "password1", "127.0.0.1")
+	if !errors.Is(err, ErrUserDisabled) {
+		t.Fatalf("Login() error = %v, want %v", err, ErrUserDisabled)
+	}
+	details, ok := apperr.From(err).Details.(BanDetails)
+	if !ok || details.Reason != "spam" || details.BannedUntil == nil || !details.BannedUntil.Equal(until) {
+		t.Errorf("Login() details = %+v, want reason and expiry", apperr.From(err).Details)
+	}
+
+	// 临时封禁到期后登录时自动解除
+	status, past := models.UserStatusDisabled, time.Now().Add(-time.Minute)
+	if err := repos.Users.Update(ctx, id, repository.UserUpdate{Status: &status, BannedUntil: &past}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Login(ctx, "alice", "password1", "127.0.0.1"); err != nil {
+		t.Fatalf("Login() after ban expired error = %v", err)
+	}
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Status != models.UserStatusActive || user.BanReason != "" {
+		t.Errorf("user after ban expired: status = %d, reason = %q", user.Status, user.BanReason)
+	}
+}
+
+func TestBanUserRejectsPastExpiry(t *testing.T) {
+	s, _ := newUserService(t)
+	id := mustRegister(t, s, "alice", "password1", "")
+
+	past := time.Now().Add(-time.Hour)
+	if err := s.BanUser(context.Background(), id, "", &past); !errors.Is(err, ErrInvalidBanExpiry) {
+		t.Errorf("BanUser() error = %v, want %v", err, ErrInvalidBanExpiry)
+	}
+}
+
+func TestValidateSession(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role, err := s.ValidateSession(ctx, id, user.TokenVersion)
+	if err != nil || role != models.RoleUser {
+		t.Fatalf("ValidateSession() = %q, %v", role, err)
+	}
+
+	if err := s.ForceLogout(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateSession(ctx, id, user.TokenVersion); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateSession() after ForceLogout error = %v, want %v", err, ErrTokenRevoked)
+	}
+
+	if err := s.BanUser(ctx, id, "spam", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateSession(ctx, id, user.TokenVersion+2); !errors.Is(err, ErrUserDisabled) {
+		t.Errorf("ValidateSession() after BanUser error = %v, want %v", err, ErrUserDisabled)
+	}
+
+	if _, err := s.ValidateSession(ctx, id+1, 0); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateSession() for unknown user error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestValidateSessionAcrossInstances(t *testing.T) {
+	// 两个服务实例共享同一个数据库，模拟多实例部署或 user 管理命令
+	s, repos := newUserService(t)
+	other := NewUserService(repos.Users, repos.RecoveryCodes, repos.PasswordResets, repos.LoginAttempts)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ValidateSession(ctx, id, user.TokenVersion); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name    string
+		change  func() error
+		version int
+		want    error
+		role    string
+	}{
+		{"role", func() error { return other.SetRole(ctx, id, models.RoleAdmin) }, user.TokenVersion, nil, models.RoleAdmin},
+		{"force logout", func() error { return other.ForceLogout(ctx, id) }, user.TokenVersion, ErrTokenRevoked, ""},
+		{"ban", func() error { return other.BanUser(ctx, id, "", nil) }, user.TokenVersion + 2, ErrUserDisabled, ""},
+	}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		role, err := s.ValidateSession(ctx, id, step.version)
+		if !errors.Is(err, step.want) || role != step.role {
+			t.Errorf("ValidateSession() after %s on another instance = %q, %v, want %q, %v", step.name, role, err, step.role, step.want)
+		}
+	}
+}
+
+func TestPreferredLocaleCache(t *testing.T) {
+	s, repos := newUserService(t)
+	other := NewUserService(repos.Users, repos.RecoveryCodes, repos.PasswordResets, repos.LoginAttempts)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+
+	if got := s.PreferredLocale(ctx, id); got != "" {
+		t.Fatalf("PreferredLocale() = %q, want none", got)
+	}
+	if err := s.UpdateUser(ctx, id, "", "", "", "en"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.PreferredLocale(ctx, id); got != i18n.LocaleEn {
+		t.Errorf("PreferredLocale() after update = %q, want %q", got, i18n.LocaleEn)
+	}
+
+	// 其他实例的修改在缓存过期后生效
+	if err := other.UpdateUser(ctx, id, "", "", "", "zh-CN"); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.PreferredLocale(ctx, id); got != i18n.LocaleEn {
+		t.Errorf("PreferredLocale() before expiry = %q, want cached %q", got, i18n.LocaleEn)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ChangePassword(ctx, id, "password2", "password3"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("ChangePassword() with wrong password error = %v, want %v", err, ErrWrongPassword)
+	}
+	if err := s.ChangePassword(ctx, id, "password1", "password3"); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if _, err := s.Login(ctx, "alice", "password1", "127.0.0.1"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() with old password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if _, err := s.Login(ctx, "alice", "password3", "127.0.0.1"); err != nil {
+		t.Errorf("Login() with new password error = %v", err)
+	}
+	if _, err := s.ValidateSession(ctx, id, user.TokenVersion); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateSession() after ChangePassword error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestResetPasswordRevokesSessions(t *testing.T) {
+	s, _ := newUserService(t)
+	ctx := context.Background()
+	id := mustRegister(t, s, "alice", "password1", "")
+	user, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ResetPassword(ctx, id, "password2"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if _, err := s.ValidateSession(ctx, id, user.TokenVersion); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateSession() after ResetPassword error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
@@ -12,22 +12,23 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
 	"github.com/xiaoxin/blog-backend/pkg/config"
 )
 
 // SaveUploadedFile 保存上传的文件
 func SaveUploadedFile(file *multipart.FileHeader) (string, error) {
-	cfg := config.GlobalConfig.Upload
+	cfg := config.Get().Upload
 
 	// 检查文件大小
 	if file.Size > int64(cfg.MaxSize)*1024*1024 {
-		return "", fmt.Errorf("文件大小超过限制，最大允许 %dMB", cfg.MaxSize)
+		return "", apperr.New(apperr.ErrTooLarge, "file_too_large", fmt.Sprintf("文件大小超过限制，最大允许 %dMB", cfg.MaxSize)).WithParams(cfg.MaxSize)
 	}
 
 	// 检查文件扩展名
 	ext := strings.ToLower(path.Ext(file.Filename))
 	if !isAllowedExt(ext, cfg.AllowedExts) {
-		return "", fmt.Errorf("不支持的文件类型: %s", ext)
+		return "", apperr.BadRequest("unsupported_file_type", fmt.Sprintf("不支持的文件类型: %s", ext)).WithParams(ext)
 	}
 
 	// 打开上传的文件
@@ -84,7 +85,7 @@ func generateUniqueFilename(ext string) string {
 
 // DeleteFile 删除文件
 func DeleteFile(relativePath string) error {
-	cfg := config.GlobalConfig.Upload
+	cfg := config.Get().Upload
 	fullPath := filepath.Join(cfg.SavePath, relativePath)
 
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
@@ -103,6 +104,6 @@ func GetFileURL(relativePath string) string {
 	if relativePath == "" {
 		return ""
 	}
-	cfg := config.GlobalConfig
+	cfg := config.Get()
 	return fmt.Sprintf("http://localhost:%d/uploads/%s", cfg.App.Port, strings.ReplaceAll(relativePath, "\\", "/"))
 }
@@ -4,13 +4,19 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
 )
 
 // Response 统一响应结构
 type Response struct {
-	Code int         `json:"code"`
-	Msg  string      `json:"msg"`
-	Data interface{} `json:"data,omitempty"`
+	Code    int         `json:"code"`
+	Msg     string      `json:"msg"`
+	Error   string      `json:"error,omitempty"`   // 机器可读的错误码，仅错误响应返回
+	Details interface{} `json:"details,omitempty"` // 错误详情，如字段校验错误列表
+	Data    interface{} `json:"data,omitempty"`
 }
 
 // PageData 分页数据结构
@@ -21,77 +27,107 @@ type PageData struct {
 	Size  int         `json:"size"`
 }
 
+// CursorPageData 游标分页数据结构
+type CursorPageData struct {
+	List       interface{} `json:"list"`
+	Size       int         `json:"size"`
+	NextCursor string      `json:"next_cursor,omitempty"` // 下一页游标，没有下一页时不返回
+	PrevCursor string      `json:"prev_cursor,omitempty"` // 上一页游标，没有上一页时不返回
+	Total      *int64      `json:"total,omitempty"`       // 总数，仅在请求统计时返回
+}
+
+// httpStatus 获取响应的 HTTP 状态码
+// 开启 app.legacy_status_code 时统一返回 200，兼容只读取 Response.Code 的旧客户端
+func httpStatus(code int) int {
+	if cfg := config.Get(); cfg != nil && cfg.App.LegacyStatusCode {
+		return http.StatusOK
+	}
+	return code
+}
+
+// Locale 获取当前请求的语言
+func Locale(c *gin.Context) string {
+	return i18n.FromContext(c.Request.Context())
+}
+
+// T 按当前请求的语言翻译消息
+func T(c *gin.Context, msgID string, args ...interface{}) string {
+	return i18n.T(Locale(c), msgID, args...)
+}
+
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Code: 200,
-		Msg:  "success",
+		Msg:  T(c, "success"),
 		Data: data,
 	})
 }
 
-// SuccessWithMsg 成功响应（带消息）
-func SuccessWithMsg(c *gin.Context, msg string, data interface{}) {
+// SuccessWithMsg 成功响应（带消息），msgID 为消息目录中的消息ID
+func SuccessWithMsg(c *gin.Context, msgID string, data interface{}) {
 	c.JSON(http.StatusOK, Response{
 		Code: 200,
-		Msg:  msg,
+		Msg:  T(c, msgID),
 		Data: data,
 	})
 }
 
 // Error 错误响应
-func Error(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, Response{
-		Code: 500,
-		Msg:  msg,
-	})
+func Error(c *gin.Context, msgID string, args ...interface{}) {
+	ErrorWithCode(c, http.StatusInternalServerError, msgID, args...)
 }
 
 // ErrorWithCode 错误响应（带状态码）
-func ErrorWithCode(c *gin.Context, code int, msg string) {
-	c.JSON(http.StatusOK, Response{
+func ErrorWithCode(c *gin.Context, code int, msgID string, args ...interface{}) {
+	c.JSON(httpStatus(code), Response{
 		Code: code,
-		Msg:  msg,
+		Msg:  T(c, msgID, args...),
 	})
 }
 
-// BadRequest 错误请求
-func BadRequest(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, Response{
-		Code: 400,
-		Msg:  msg,
+// Fail 按应用错误响应，提示信息按请求语言翻译；非应用错误视为内部错误，不返回错误详情
+func Fail(c *gin.Context, err error) {
+	e := apperr.From(err)
+	c.JSON(httpStatus(e.Status), Response{
+		Code:    e.Status,
+		Msg:     e.Localize(Locale(c)),
+		Error:   e.Code,
+		Details: e.Details,
 	})
 }
 
+// AbortWithError 中止请求并交由错误处理中间件响应
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// BadRequest 错误请求
+func BadRequest(c *gin.Context, msgID string, args ...interface{}) {
+	Fail(c, apperr.ErrBadRequest.WithMessageID(msgID, args...))
+}
+
 // Unauthorized 未授权
-func Unauthorized(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, Response{
-		Code: 401,
-		Msg:  msg,
-	})
+func Unauthorized(c *gin.Context, msgID string, args ...interface{}) {
+	Fail(c, apperr.ErrUnauthorized.WithMessageID(msgID, args...))
 }
 
 // Forbidden 禁止访问
-func Forbidden(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, Response{
-		Code: 403,
-		Msg:  msg,
-	})
+func Forbidden(c *gin.Context, msgID string, args ...interface{}) {
+	Fail(c, apperr.ErrForbidden.WithMessageID(msgID, args...))
 }
 
 // NotFound 未找到
-func NotFound(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, Response{
-		Code: 404,
-		Msg:  msg,
-	})
+func NotFound(c *gin.Context, msgID string, args ...interface{}) {
+	Fail(c, apperr.ErrNotFound.WithMessageID(msgID, args...))
 }
 
 // PageSuccess 分页成功响应
 func PageSuccess(c *gin.Context, list interface{}, total int64, page, size int) {
 	c.JSON(http.StatusOK, Response{
 		Code: 200,
-		Msg:  "success",
+		Msg:  T(c, "success"),
 		Data: PageData{
 			List:  list,
 			Total: total,
@@ -100,3 +136,12 @@ func PageSuccess(c *gin.Context, list interface{}, total int64, page, size int)
 		},
 	})
 }
+
+// CursorPageSuccess 游标分页成功响应
+func CursorPageSuccess(c *gin.Context, data CursorPageData) {
+	c.JSON(http.StatusOK, Response{
+		Code: 200,
+		Msg:  T(c, "success"),
+		Data: data,
+	})
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/query"
+)
+
+// ErrInvalidJSON 请求体不是合法的 JSON
+var ErrInvalidJSON = apperr.BadRequest("invalid_json", "请求体不是有效的JSON")
+
+// InvalidParams 参数绑定失败响应，校验错误按字段返回翻译后的错误列表
+func InvalidParams(c *gin.Context, err error) {
+	if details, ok := validation.Translate(err, Locale(c)); ok {
+		Fail(c, validation.ErrValidation.WithDetails(details))
+		return
+	}
+
+	// 字段类型不匹配，如字符串传给数字字段
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		Fail(c, validation.ErrValidation.WithDetails([]validation.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: T(c, "field_type_mismatch", typeErr.Field, typeErr.Type.String()),
+		}}))
+		return
+	}
+
+	// 白名单参数解析错误，如不支持的排序字段
+	var queryErrs query.Errors
+	if errors.As(err, &queryErrs) {
+		details := make([]validation.FieldError, len(queryErrs))
+		for i, e := range queryErrs {
+			details[i] = validation.FieldError{Field: e.Param, Rule: "format", Message: T(c, "query_malformed", e.Param, e.Value)}
+			if len(e.Allowed) > 0 {
+				details[i].Rule = "oneof"
+				details[i].Param = strings.Join(e.Allowed, " ")
+				details[i].Message = T(c, "query_not_allowed", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+			}
+		}
+		Fail(c, validation.ErrValidation.WithDetails(details))
+		return
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		Fail(c, ErrInvalidJSON)
+		return
+	}
+
+	// 查询参数和表单的类型转换错误，如 ?page_size=abc
+	if value, typ, ok := formConversion(err); ok {
+		field := formField(c, value)
+		Fail(c, validation.ErrValidation.WithDetails([]validation.FieldError{{
+			Field:   field,
+			Rule:    "type",
+			Param:   typ,
+			Message: T(c, "field_type_mismatch", field, typ),
+		}}))
+		return
+	}
+
+	// 其他错误的原文可能包含内部细节，不返回给客户端
+	Fail(c, validation.ErrValidation.WithCause(err))
+}
+
+// formConversion 解析表单绑定的类型转换错误，返回无法转换的值和期望的类型
+func formConversion(err error) (value, typ string, ok bool) {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		switch numErr.Func {
+		case "ParseBool":
+			return numErr.Num, "bool", true
+		case "ParseFloat":
+			return numErr.Num, "number", true
+		default:
+			return numErr.Num, "integer", true
+		}
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return timeErr.Value, "time", true
+	}
+	return "", "", false
+}
+
+// formField 查找取值为 value 的参数名，表单绑定的转换错误中不包含参数名
+func formField(c *gin.Context, value string) string {
+	for _, values := range []url.Values{c.Request.URL.Query(), c.Request.PostForm} {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if slices.Contains(values[key], value) {
+				return key
+			}
+		}
+	}
+	for _, param := range c.Params {
+		if param.Value == value {
+			return param.Key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/xiaoxin/blog-backend/internal/validation"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+)
+
+type invalidParamsResponse struct {
+	Code    int                     `json:"code"`
+	Msg     string                  `json:"msg"`
+	Error   string                  `json:"error"`
+	Details []validation.FieldError `json:"details"`
+}
+
+// invalidParams 用 bind 绑定请求，返回 InvalidParams 的响应
+func invalidParams(t *testing.T, target, body string, bind func(c *gin.Context) error) invalidParamsResponse {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), i18n.LocaleEn))
+		if err := bind(c); err != nil {
+			InvalidParams(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp invalidParamsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestInvalidParams(t *testing.T) {
+	type listQuery struct {
+		PageSize  int       `form:"page_size"`
+		Published bool      `form:"published"`
+		Since     time.Time `form:"since" time_format:"2006-01-02"`
+	}
+	type body struct {
+		Title string `json:"title"`
+	}
+	bindQuery := func(c *gin.Context) error { return c.ShouldBindQuery(&listQuery{}) }
+	bindJSON := func(c *gin.Context) error { return c.ShouldBindJSON(&body{}) }
+
+	tests := []struct {
+		name      string
+		target    string
+		body      string
+		bind      func(c *gin.Context) error
+		wantError string
+		want      []validation.FieldError
+	}{
+		{"integer", "/?page_size=abc", "", bindQuery, "validation_failed", []validation.FieldError{
+			{Field: "page_size", Rule: "type", Param: "integer", Message: "page_size must be of type integer"},
+		}},
+		{"bool", "/?page_size=1&published=maybe", "", bindQuery, "validation_failed", []validation.FieldError{
+			{Field: "published", Rule: "type", Param: "bool", Message: "published must be of type bool"},
+		}},
+		{"time", "/?since=yesterday", "", bindQuery, "validation_failed", []validation.FieldError{
+			{Field: "since", Rule: "type", Param: "time", Message: "since must be of type time"},
+		}},
+		{"json type", "/", `{"title": 1}`, bindJSON, "validation_failed", []validation.FieldError{
+			{Field: "title", Rule: "type", Param: "string", Message: "title must be of type string"},
+		}},
+		{"malformed json", "/", `{"title"`, bindJSON, "invalid_json", nil},
+		{"other error", "/", "", func(c *gin.Context) error { return errors.New("dial tcp 10.0.0.1:3306: refused") }, "validation_failed", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := invalidParams(t, tt.target, tt.body, tt.bind)
+			if resp.Code != http.StatusBadRequest || resp.Error != tt.wantError {
+				t.Errorf("response code = %d error = %q, want 400 %q", resp.Code, resp.Error, tt.wantError)
+			}
+			if len(resp.Details) != len(tt.want) {
+				t.Fatalf("details = %+v, want %+v", resp.Details, tt.want)
+			}
+			for i := range tt.want {
+				if resp.Details[i] != tt.want[i] {
+					t.Errorf("details[%d] = %+v, want %+v", i, resp.Details[i], tt.want[i])
+				}
+			}
+			// 原始错误文本不返回给客户端
+			if strings.Contains(resp.Msg, "strconv") || strings.Contains(resp.Msg, "dial tcp") || strings.Contains(resp.Msg, "parsing") {
+				t.Errorf("response msg leaks the error: %q", resp.Msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+// Package validation 请求参数校验
+//
+// 在 gin 的校验器上注册自定义规则和多语言翻译，并将校验错误转换为按 JSON 字段名描述的结构化列表。
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entrans "github.com/go-playground/validator/v10/translations/en"
+	zhtrans "github.com/go-playground/validator/v10/translations/zh"
+
+	"github.com/xiaoxin/blog-backend/pkg/apperr"
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+)
+
+// ErrValidation 请求参数校验失败，details 中为各字段的错误
+var ErrValidation = apperr.BadRequest("validation_failed", "参数校验失败")
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`           // JSON 字段名，嵌套字段用 . 连接
+	Rule    string `json:"rule"`            // 未通过的规则，如 required、max
+	Param   string `json:"param,omitempty"` // 规则参数，如 max=200 中的 200
+	Message string `json:"message"`         // 按请求语言翻译的提示信息
+}
+
+// UsernamePattern 用户名只能包含字母、数字和下划线，且以字母开头
+var UsernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// translators 各语言的翻译器，键为 i18n 的语言
+var translators = map[string]ut.Translator{}
+
+// rule 自定义校验规则
+type rule struct {
+	tag      string
+	fn       validator.Func
+	messages map[string]string // 各语言的提示模板，{0} 为字段名，{1} 为规则参数
+}
+
+// Init 注册自定义校验规则和翻译
+// 规则只检查请求本身的格式，需要查询数据的校验（如分类是否存在）由服务层在请求的 context 中完成
+func Init() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin 校验器不是 go-playground/validator")
+	}
+
+	// 错误中使用 JSON 字段名，查询参数结构体使用 form 参数名
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" {
+			name = strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		}
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	uni := ut.New(en.New(), en.New(), zh.New())
+	translators = map[string]ut.Translator{}
+	for locale, register := range map[string]struct {
+		name string
+		fn   func(*validator.Validate, ut.Translator) error
+	}{
+		i18n.LocaleEn:   {"en", entrans.RegisterDefaultTranslations},
+		i18n.LocaleZhCN: {"zh", zhtrans.RegisterDefaultTranslations},
+	} {
+		trans, _ := uni.GetTranslator(register.name)
+		if err := register.fn(v, trans); err != nil {
+			return fmt.Errorf("注册 %s 校验翻译失败: %w", locale, err)
+		}
+		translators[locale] = trans
+	}
+
+	rules := []rule{
+		{
+			tag: "username",
+			fn: func(fl validator.FieldLevel) bool {
+				return UsernamePattern.MatchString(fl.Field().String())
+			},
+			messages: map[string]string{
+				i18n.LocaleZhCN: "{0}只能包含字母、数字和下划线，且必须以字母开头",
+				i18n.LocaleEn:   "{0} must start with a letter and contain only letters, digits and underscores",
+			},
+		},
+		{
+			tag: "password",
+			fn:  isStrongPassword,
+			messages: map[string]string{
+				i18n.LocaleZhCN: "{0}必须同时包含字母和数字",
+				i18n.LocaleEn:   "{0} must contain both letters and digits",
+			},
+		},
+	}
+	for _, r := range rules {
+		if err := registerRule(v, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerRule 注册自定义规则及其翻译
+func registerRule(v *validator.Validate, r rule) error {
+	if err := v.RegisterValidation(r.tag, r.fn); err != nil {
+		return fmt.Errorf("注册校验规则 %s 失败: %w", r.tag, err)
+	}
+
+	for locale, trans := range translators {
+		msg := r.messages[locale]
+		err := v.RegisterTranslation(r.tag, trans, func(trans ut.Translator) error {
+			return trans.Add(r.tag, msg, true)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			s, _ := trans.T(fe.Tag(), fe.Field(), fe.Param())
+			return s
+		})
+		if err != nil {
+			return fmt.Errorf("注册校验规则 %s 的翻译失败: %w", r.tag, err)
+		}
+	}
+	return nil
+}
+
+// isStrongPassword 密码必须同时包含字母和数字
+func isStrongPassword(fl validator.FieldLevel) bool {
+	var hasLetter, hasDigit bool
+	for _, r := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	return hasLetter && hasDigit
+}
+
+// Translate 将校验错误转换为按语言翻译的字段错误列表，err 不是校验错误时返回 false
+func Translate(err error, locale string) ([]FieldError, bool) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+
+	trans, ok := translators[locale]
+	if !ok {
+		trans = translators[i18n.Default()]
+	}
+
+	details := make([]FieldError, 0, len(errs))
+	for _, fe := range errs {
+		msg := fe.Error()
+		if trans != nil {
+			msg = fe.Translate(trans)
+		}
+		details = append(details, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: msg,
+		})
+	}
+	return details, true
+}
+
+// fieldPath 去掉命名空间开头的结构体名，如 CreateArticleRequest.title -> title
+func fieldPath(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+)
+
+func TestMain(m *testing.M) {
+	if err := Init(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+type testAuthor struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type testRequest struct {
+	Username string     `json:"username" binding:"required,username"`
+	Password string     `json:"password" binding:"required,password"`
+	PageSize int        `form:"page_size" binding:"max=100"`
+	Author   testAuthor `json:"author"`
+	Internal string     `json:"-" binding:"required"`
+}
+
+func TestTranslate(t *testing.T) {
+	req := testRequest{Username: "1alice", Password: "password", PageSize: 101, Internal: "set"}
+	err := binding.Validator.ValidateStruct(&req)
+
+	tests := []struct {
+		locale string
+		want   []FieldError
+	}{
+		{i18n.LocaleEn, []FieldError{
+			{Field: "username", Rule: "username", Message: "username must start with a letter and contain only letters, digits and underscores"},
+			{Field: "password", Rule: "password", Message: "password must contain both letters and digits"},
+			{Field: "page_size", Rule: "max", Param: "100", Message: "page_size must be 100 or less"},
+			{Field: "author.name", Rule: "required", Message: "name is a required field"},
+		}},
+		{i18n.LocaleZhCN, []FieldError{
+			{Field: "username", Rule: "username", Message: "username只能包含字母、数字和下划线，且必须以字母开头"},
+			{Field: "password", Rule: "password", Message: "password必须同时包含字母和数字"},
+			{Field: "page_size", Rule: "max", Param: "100", Message: "page_size必须小于或等于100"},
+			{Field: "author.name", Rule: "required", Message: "name为必填字段"},
+		}},
+		// 不支持的语言使用默认语言
+		{"fr", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			got, ok := Translate(err, tt.locale)
+			if !ok {
+				t.Fatalf("Translate(%v) not a validation error", err)
+			}
+			want := tt.want
+			if want == nil {
+				want, _ = Translate(err, i18n.Default())
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("Translate() =\n%+v\nwant\n%+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	tests := []struct {
+		username string
+		password string
+		wantErr  []string
+	}{
+		{"alice", "password1", nil},
+		{"a_1", "1a", nil},
+		{"_alice", "password1", []string{"username"}},
+		{"alice-bob", "password1", []string{"username"}},
+		{"alice", "12345678", []string{"password"}},
+		{"alice", "密码abc", []string{"password"}},
+		{"爱丽丝", "密码123", []string{"username"}},
+	}
+	for _, tt := range tests {
+		req := testRequest{Username: tt.username, Password: tt.password, Author: testAuthor{Name: "a"}, Internal: "set"}
+		details, _ := Translate(binding.Validator.ValidateStruct(&req), i18n.LocaleEn)
+		var got []string
+		for _, d := range details {
+			got = append(got, d.Rule)
+		}
+		if !slices.Equal(got, tt.wantErr) {
+			t.Errorf("validate(%q, %q) failed rules = %v, want %v", tt.username, tt.password, got, tt.wantErr)
+		}
+	}
+}
+
+func TestTranslateOtherErrors(t *testing.T) {
+	for _, err := range []error{nil, errors.New("boom")} {
+		if details, ok := Translate(err, i18n.LocaleEn); ok {
+			t.Errorf("Translate(%v) = %v, want not a validation error", err, details)
+		}
+	}
+}
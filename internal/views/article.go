@@ -0,0 +1,144 @@
+// Package views 接口响应视图
+//
+// 控制器不直接序列化模型，而是转换为视图，只输出对应场景需要的字段，避免泄露用户邮箱、角色等私有信息。
+package views
+
+import (
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+)
+
+// AuthorCard 作者的公开信息
+type AuthorCard struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+// NewAuthorCard 创建作者卡片，作者未加载时返回 nil
+func NewAuthorCard(user *models.User) *AuthorCard {
+	if user.ID == 0 {
+		return nil
+	}
+	return &AuthorCard{
+		ID:       user.ID,
+		Username: user.Username,
+		Nickname: user.Nickname,
+		Avatar:   user.Avatar,
+	}
+}
+
+// CategoryBrief 分类摘要
+type CategoryBrief struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// TagBrief 标签摘要
+type TagBrief struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// ArticleListItem 文章列表项，不包含正文
+type ArticleListItem struct {
+	ID           uint           `json:"id"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Cover        string         `json:"cover"`
+	AuthorID     uint           `json:"author_id"`
+	Author       *AuthorCard    `json:"author,omitempty"`
+	CategoryID   uint           `json:"category_id"`
+	Category     *CategoryBrief `json:"category,omitempty"`
+	Tags         []TagBrief     `json:"tags"`
+	ViewCount    int            `json:"view_count"`
+	LikeCount    int            `json:"like_count"`
+	CommentCount int            `json:"comment_count"`
+	IsTop        bool           `json:"is_top"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// NewArticleListItem 创建文章列表项
+func NewArticleListItem(article *models.Article) ArticleListItem {
+	item := ArticleListItem{
+		ID:           article.ID,
+		Title:        article.Title,
+		Description:  article.Description,
+		Cover:        article.Cover,
+		AuthorID:     article.AuthorID,
+		Author:       NewAuthorCard(&article.Author),
+		CategoryID:   article.CategoryID,
+		Tags:         make([]TagBrief, 0, len(article.Tags)),
+		ViewCount:    article.ViewCount,
+		LikeCount:    article.LikeCount,
+		CommentCount: article.CommentCount,
+		IsTop:        article.IsTop,
+		CreatedAt:    article.CreatedAt,
+		UpdatedAt:    article.UpdatedAt,
+	}
+	if article.Category.ID != 0 {
+		item.Category = &CategoryBrief{ID: article.Category.ID, Name: article.Category.Name}
+	}
+	for _, tag := range article.Tags {
+		item.Tags = append(item.Tags, TagBrief{ID: tag.ID, Name: tag.Name})
+	}
+	return item
+}
+
+// NewArticleList 创建文章列表
+func NewArticleList(articles []models.Article) []ArticleListItem {
+	items := make([]ArticleListItem, len(articles))
+	for i := range articles {
+		items[i] = NewArticleListItem(&articles[i])
+	}
+	return items
+}
+
+// ArticleDetail 文章详情，公开访问时使用
+type ArticleDetail struct {
+	ArticleListItem
+	Content string `json:"content"`
+}
+
+// NewArticleDetail 创建文章详情
+func NewArticleDetail(article *models.Article) ArticleDetail {
+	return ArticleDetail{
+		ArticleListItem: NewArticleListItem(article),
+		Content:         article.Content,
+	}
+}
+
+// ArticleOwnerView 作者本人或管理员看到的文章详情，包含发布状态
+type ArticleOwnerView struct {
+	ArticleDetail
+	Status int `json:"status"` // 1:已发布 0:草稿
+}
+
+// NewArticleOwnerView 创建作者和管理员的文章详情
+func NewArticleOwnerView(article *models.Article) ArticleOwnerView {
+	return ArticleOwnerView{
+		ArticleDetail: NewArticleDetail(article),
+		Status:        article.Status,
+	}
+}
+
+// ArticleOwnerItem 作者本人或管理员看到的文章列表项，包含发布状态
+type ArticleOwnerItem struct {
+	ArticleListItem
+	Status int `json:"status"` // 1:已发布 0:草稿
+}
+
+// NewArticleOwnerList 创建作者和管理员的文章列表
+func NewArticleOwnerList(articles []models.Article) []ArticleOwnerItem {
+	items := make([]ArticleOwnerItem, len(articles))
+	for i := range articles {
+		items[i] = ArticleOwnerItem{
+			ArticleListItem: NewArticleListItem(&articles[i]),
+			Status:          articles[i].Status,
+		}
+	}
+	return items
+}
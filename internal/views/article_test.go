@@ -0,0 +1,116 @@
+package views
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+)
+
+// privateArticle 作者信息完整加载的文章，用于检查视图不会输出私有字段
+func privateArticle() *models.Article {
+	verified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &models.Article{
+		BaseModel: models.BaseModel{ID: 1},
+		Title:     "title",
+		Content:   "content",
+		AuthorID:  7,
+		Author: models.User{
+			BaseModel:       models.BaseModel{ID: 7},
+			Username:        "alice",
+			Password:        "$2a$10$secret-hash",
+			Email:           "alice@example.com",
+			EmailVerifiedAt: &verified,
+			PendingEmail:    "alice@new.example.com",
+			Nickname:        "Alice",
+			Avatar:          "/uploads/alice.png",
+			Role:            models.RoleAdmin,
+			Status:          0,
+			Locale:          "en",
+			BanReason:       "spam",
+			BannedUntil:     &verified,
+			TOTPSecret:      "GEZDGNBVGY3TQOJQ",
+			TOTPEnabledAt:   &verified,
+		},
+		Status: 1,
+	}
+}
+
+// jsonKeys 对象的键，按字母顺序排列
+func jsonKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestPublicViewsHidePrivateFields(t *testing.T) {
+	article := privateArticle()
+
+	tests := []struct {
+		name       string
+		view       interface{}
+		wantStatus bool
+	}{
+		{"list item", NewArticleListItem(article), false},
+		{"list", NewArticleList([]models.Article{*article})[0], false},
+		{"detail", NewArticleDetail(article), false},
+		{"owner view", NewArticleOwnerView(article), true},
+		{"owner list", NewArticleOwnerList([]models.Article{*article})[0], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.view)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var view struct {
+				Author json.RawMessage `json:"author"`
+			}
+			if err := json.Unmarshal(data, &view); err != nil {
+				t.Fatal(err)
+			}
+			// 作者卡片只包含公开信息，作者本人和管理员看到的视图也一样
+			if keys := jsonKeys(t, view.Author); !slices.Equal(keys, []string{"avatar", "id", "nickname", "username"}) {
+				t.Errorf("author keys = %v", keys)
+			}
+			for _, private := range []string{"alice@example.com", "alice@new.example.com", "secret-hash", "GEZDGNBVGY3TQOJQ", "spam", `"role"`, `"admin"`, `"email`, `"locale"`} {
+				if strings.Contains(string(data), private) {
+					t.Errorf("view contains %s: %s", private, data)
+				}
+			}
+
+			// 发布状态只在作者本人和管理员的视图中返回
+			if hasStatus := slices.Contains(jsonKeys(t, data), "status"); hasStatus != tt.wantStatus {
+				t.Errorf("view has status = %v, want %v", hasStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthorCardNotLoaded(t *testing.T) {
+	if card := NewAuthorCard(&models.User{}); card != nil {
+		t.Errorf("NewAuthorCard() for unloaded author = %+v, want nil", card)
+	}
+
+	article := privateArticle()
+	article.Author = models.User{}
+	data, err := json.Marshal(NewArticleListItem(article))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(jsonKeys(t, data), "author") {
+		t.Errorf("list item without author = %s", data)
+	}
+}
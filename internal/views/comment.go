@@ -0,0 +1,45 @@
+package views
+
+import (
+	"time"
+
+	"github.com/xiaoxin/blog-backend/internal/models"
+)
+
+// ArticleBrief 文章摘要
+type ArticleBrief struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// CommentItem 评论列表项，包含所属文章的标题
+type CommentItem struct {
+	ID        uint          `json:"id"`
+	ArticleID uint          `json:"article_id"`
+	Article   *ArticleBrief `json:"article,omitempty"`
+	UserID    uint          `json:"user_id"`
+	Content   string        `json:"content"`
+	ParentID  *uint         `json:"parent_id"`
+	Status    int           `json:"status"` // 1:正常 0:已删除
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// NewCommentList 创建评论列表
+func NewCommentList(comments []models.Comment) []CommentItem {
+	items := make([]CommentItem, len(comments))
+	for i, comment := range comments {
+		items[i] = CommentItem{
+			ID:        comment.ID,
+			ArticleID: comment.ArticleID,
+			UserID:    comment.UserID,
+			Content:   comment.Content,
+			ParentID:  comment.ParentID,
+			Status:    comment.Status,
+			CreatedAt: comment.CreatedAt,
+		}
+		if comment.Article.ID != 0 {
+			items[i].Article = &ArticleBrief{ID: comment.Article.ID, Title: comment.Article.Title}
+		}
+	}
+	return items
+}
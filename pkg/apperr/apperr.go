@@ -0,0 +1,168 @@
+// Package apperr 应用错误，携带稳定的错误码和对应的 HTTP 状态码
+//
+// 每个错误都属于一个错误类别（ErrNotFound、ErrConflict 等），可以用 errors.Is 判断：
+//
+//	var ErrArticleNotFound = apperr.NotFound("article_not_found", "文章不存在")
+//	errors.Is(ErrArticleNotFound, apperr.ErrNotFound) // true
+//
+// 响应时按 MessageID 从 i18n 消息目录翻译提示信息，目录中没有时使用 Message。
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+)
+
+// Error 应用错误
+type Error struct {
+	Code    string // 稳定的机器可读错误码，如 article_not_found
+	Message string // 默认语言的提示信息
+	Status  int    // HTTP 状态码
+
+	MessageID string        // 消息目录中的消息ID，为空时不翻译
+	Params    []interface{} // 消息模板参数
+	Details   interface{}   // 返回给客户端的错误详情，如字段校验错误列表
+
+	kind  *Error // 上一级错误（派生来源或所属类别），类别本身为 nil
+	cause error  // 原始错误，仅用于日志，不返回给客户端
+}
+
+// 错误类别
+var (
+	ErrBadRequest      = &Error{Code: "bad_request", Message: "请求参数错误", Status: http.StatusBadRequest, MessageID: "bad_request"}
+	ErrUnauthorized    = &Error{Code: "unauthorized", Message: "未授权", Status: http.StatusUnauthorized, MessageID: "unauthorized"}
+	ErrForbidden       = &Error{Code: "forbidden", Message: "权限不足", Status: http.StatusForbidden, MessageID: "forbidden"}
+	ErrNotFound        = &Error{Code: "not_found", Message: "资源不存在", Status: http.StatusNotFound, MessageID: "not_found"}
+	ErrConflict        = &Error{Code: "conflict", Message: "资源冲突", Status: http.StatusConflict, MessageID: "conflict"}
+	ErrTooLarge        = &Error{Code: "too_large", Message: "请求内容过大", Status: http.StatusRequestEntityTooLarge, MessageID: "too_large"}
+	ErrTooManyRequests = &Error{Code: "too_many_requests", Message: "请求过于频繁，请稍后再试", Status: http.StatusTooManyRequests, MessageID: "too_many_requests"}
+	ErrInternal        = &Error{Code: "internal_error", Message: "服务器内部错误", Status: http.StatusInternalServerError, MessageID: "internal_error"}
+	ErrBadGateway      = &Error{Code: "bad_gateway", Message: "依赖的外部服务暂时不可用", Status: http.StatusBadGateway, MessageID: "bad_gateway"}
+)
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap 返回所属类别和原始错误，供 errors.Is/As 使用
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.kind != nil {
+		errs = append(errs, e.kind)
+	}
+	if e.cause != nil {
+		errs = append(errs, e.cause)
+	}
+	return errs
+}
+
+// WithMessage 返回替换了提示信息的副本，错误码不变，errors.Is 仍能匹配原错误
+// 提示信息原样返回，不再翻译
+func (e *Error) WithMessage(msg string) *Error {
+	c := *e
+	c.Message = msg
+	c.MessageID = ""
+	c.Params = nil
+	c.kind = e
+	return &c
+}
+
+// WithMessageID 返回按消息ID翻译提示信息的副本，错误码不变
+func (e *Error) WithMessageID(id string, params ...interface{}) *Error {
+	c := *e
+	c.Message = i18n.T(i18n.Default(), id, params...)
+	c.MessageID = id
+	c.Params = params
+	c.kind = e
+	return &c
+}
+
+// WithParams 返回附带消息模板参数的副本
+func (e *Error) WithParams(params ...interface{}) *Error {
+	c := *e
+	c.Params = params
+	c.kind = e
+	return &c
+}
+
+// WithDetails 返回附带错误详情的副本
+func (e *Error) WithDetails(details interface{}) *Error {
+	c := *e
+	c.Details = details
+	c.kind = e
+	return &c
+}
+
+// Localize 按语言翻译提示信息，消息目录中没有时返回 Message
+func (e *Error) Localize(locale string) string {
+	if e.MessageID == "" {
+		return e.Message
+	}
+	msg, ok := i18n.Lookup(locale, e.MessageID)
+	if !ok {
+		return e.Message
+	}
+	if len(e.Params) > 0 {
+		return fmt.Sprintf(msg, e.Params...)
+	}
+	return msg
+}
+
+// WithCause 返回附带原始错误的副本，原始错误只记录日志，errors.Is 仍能匹配原错误
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.cause = err
+	c.kind = e
+	return &c
+}
+
+// New 创建属于指定类别的错误，错误码同时作为消息ID
+func New(kind *Error, code, msg string) *Error {
+	return &Error{Code: code, Message: msg, Status: kind.Status, MessageID: code, kind: kind}
+}
+
+// BadRequest 创建请求参数错误
+func BadRequest(code, msg string) *Error {
+	return New(ErrBadRequest, code, msg)
+}
+
+// Unauthorized 创建未授权错误
+func Unauthorized(code, msg string) *Error {
+	return New(ErrUnauthorized, code, msg)
+}
+
+// Forbidden 创建禁止访问错误
+func Forbidden(code, msg string) *Error {
+	return New(ErrForbidden, code, msg)
+}
+
+// NotFound 创建资源不存在错误
+func NotFound(code, msg string) *Error {
+	return New(ErrNotFound, code, msg)
+}
+
+// Conflict 创建资源冲突错误
+func Conflict(code, msg string) *Error {
+	return New(ErrConflict, code, msg)
+}
+
+// Internal 包装内部错误，客户端只能看到通用提示
+func Internal(err error) *Error {
+	return ErrInternal.WithCause(err)
+}
+
+// From 将任意错误转换为应用错误，非应用错误视为内部错误
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal(err)
+}
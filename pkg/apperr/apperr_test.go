@@ -0,0 +1,114 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestIsCategory(t *testing.T) {
+	notFound := NotFound("article_not_found", "文章不存在")
+	cause := errors.New("connection refused")
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"category", notFound, ErrNotFound, true},
+		{"other category", notFound, ErrConflict, false},
+		{"itself", notFound, notFound, true},
+		{"with message", notFound.WithMessage("gone"), notFound, true},
+		{"with message category", notFound.WithMessage("gone"), ErrNotFound, true},
+		{"with details", notFound.WithDetails([]string{"id"}), ErrNotFound, true},
+		{"with message id", notFound.WithMessageID("not_found"), notFound, true},
+		{"with cause", notFound.WithCause(cause), cause, true},
+		{"wrapped", fmt.Errorf("load: %w", notFound), ErrNotFound, true},
+		{"sibling", notFound, NotFound("user_not_found", "用户不存在"), false},
+		{"internal", Internal(cause), ErrInternal, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewInheritsStatus(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want int
+	}{
+		{BadRequest("invalid_cursor", ""), http.StatusBadRequest},
+		{Unauthorized("token_invalid", ""), http.StatusUnauthorized},
+		{Forbidden("user_disabled", ""), http.StatusForbidden},
+		{NotFound("article_not_found", ""), http.StatusNotFound},
+		{Conflict("username_taken", ""), http.StatusConflict},
+		{New(ErrTooManyRequests, "reset_too_frequent", ""), http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		if tt.err.Status != tt.want {
+			t.Errorf("%s status = %d, want %d", tt.err.Code, tt.err.Status, tt.want)
+		}
+		if tt.err.MessageID != tt.err.Code {
+			t.Errorf("%s message id = %q, want the code", tt.err.Code, tt.err.MessageID)
+		}
+	}
+}
+
+func TestFrom(t *testing.T) {
+	notFound := NotFound("article_not_found", "文章不存在")
+	cause := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{"app error", notFound, "article_not_found"},
+		{"wrapped app error", fmt.Errorf("load: %w", notFound), "article_not_found"},
+		{"plain error", cause, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Code != tt.wantCode {
+				t.Errorf("From() code = %q, want %q", got.Code, tt.wantCode)
+			}
+		})
+	}
+
+	// 原始错误只保留在错误链中，不出现在返回给客户端的提示信息里
+	e := From(cause)
+	if !errors.Is(e, cause) {
+		t.Error("From() lost the cause")
+	}
+	if e.Localize("en") == cause.Error() || e.Status != http.StatusInternalServerError {
+		t.Errorf("From() = %q status %d", e.Localize("en"), e.Status)
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *Error
+		locale string
+		want   string
+	}{
+		{"catalog", ErrNotFound, "en", "Resource not found"},
+		{"default language", ErrNotFound, "zh-CN", "资源不存在"},
+		{"not in catalog", New(ErrNotFound, "no_such_message", "默认提示"), "en", "默认提示"},
+		{"custom message", ErrNotFound.WithMessage("自定义"), "en", "自定义"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Localize(tt.locale); got != tt.want {
+				t.Errorf("Localize(%q) = %q, want %q", tt.locale, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/viper"
@@ -9,12 +15,23 @@ import (
 
 // Config 全局配置结构
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Log      LogConfig      `mapstructure:"log"`
-	Upload   UploadConfig   `mapstructure:"upload"`
+	App       AppConfig       `mapstructure:"app"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Log       LogConfig       `mapstructure:"log"`
+	Upload    UploadConfig    `mapstructure:"upload"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
+	CORS      CORSConfig      `mapstructure:"cors"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Mail      MailConfig      `mapstructure:"mail"`
+
+	EmailVerification EmailVerificationConfig `mapstructure:"email_verification"`
+	PasswordReset     PasswordResetConfig     `mapstructure:"password_reset"`
+	LoginProtection   LoginProtectionConfig   `mapstructure:"login_protection"`
+	TwoFactor         TwoFactorConfig         `mapstructure:"two_factor"`
+	OIDC              OIDCConfig              `mapstructure:"oidc"`
 }
 
 // AppConfig 应用配置
@@ -25,10 +42,27 @@ type AppConfig struct {
 	Port         int    `mapstructure:"port"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	// LegacyStatusCode 错误响应也返回 HTTP 200，真实状态码只在 Response.Code 中，兼容旧客户端
+	LegacyStatusCode bool `mapstructure:"legacy_status_code"`
+	// Locale 默认语言，请求未指定语言或无法匹配时使用，为空时使用 zh-CN
+	Locale string `mapstructure:"locale"`
+	// TrustedProxies 可信的反向代理地址（IP 或 CIDR），只有来自这些地址的请求才读取 X-Forwarded-For
+	// 确定客户端IP；为空时不信任任何代理，直接使用连接地址
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	Driver          string `mapstructure:"driver"`   // mysql, postgres, sqlite，默认 mysql
+	Path            string `mapstructure:"path"`     // SQLite 数据库文件路径
+	SSLMode         string `mapstructure:"ssl_mode"` // PostgreSQL sslmode，默认 disable
 	Host            string `mapstructure:"host"`
 	Port            int    `mapstructure:"port"`
 	Username        string `mapstructure:"username"`
@@ -40,6 +74,27 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
 	LogLevel        int    `mapstructure:"log_level"`
+	SlowThreshold   int    `mapstructure:"slow_threshold"`   // 慢查询阈值（毫秒）
+	MigrateOnStart  bool   `mapstructure:"migrate_on_start"` // 启动时执行版本化迁移
+	AutoMigrate     bool   `mapstructure:"auto_migrate"`     // 启动时执行 GORM AutoMigrate，仅限开发环境
+
+	Replicas      []ReplicaConfig `mapstructure:"replicas"`       // 只读副本，未配置的字段沿用主库配置
+	ReplicaPolicy string          `mapstructure:"replica_policy"` // random, round_robin，默认 random
+	StickyWindow  int             `mapstructure:"sticky_window"`  // 写入后读主库的时长（秒），0 表示不启用
+}
+
+// 副本负载均衡策略
+const (
+	ReplicaPolicyRandom     = "random"
+	ReplicaPolicyRoundRobin = "round_robin"
+)
+
+// ReplicaConfig 只读副本配置
+type ReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
 }
 
 // RedisConfig Redis配置
@@ -75,30 +130,236 @@ type UploadConfig struct {
 	AllowedExts []string `mapstructure:"allowed_exts"`
 }
 
-var GlobalConfig *Config
+// MetricsConfig 监控指标配置
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"` // stdout, otlp
+	Endpoint    string  `mapstructure:"endpoint"` // OTLP HTTP 地址，如 localhost:4318
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowedOrigins []string `mapstructure:"allowed_origins"` // 为空或包含 "*" 时允许所有来源
+}
+
+// RateLimitConfig 限流配置（按客户端IP）
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
+// 邮件驱动
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+)
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Driver    string `mapstructure:"driver"` // smtp, file，默认 file
+	From      string `mapstructure:"from"`   // 发件人，如 "Blog <noreply@example.com>"
+	Host      string `mapstructure:"host"`
+	Port      int    `mapstructure:"port"`
+	Username  string `mapstructure:"username"`
+	Password  string `mapstructure:"password"`
+	TLS       bool   `mapstructure:"tls"`        // 使用隐式 TLS（如 465 端口），否则在服务器支持时使用 STARTTLS
+	OutboxDir string `mapstructure:"outbox_dir"` // file 驱动写入 .eml 文件的目录
+}
+
+// 未验证邮箱时可限制的操作
+const (
+	ActionArticle = "article" // 发布和编辑文章
+	ActionLike    = "like"    // 点赞文章
+	ActionUpload  = "upload"  // 上传文件
+)
+
+// EmailVerificationConfig 邮箱验证配置
+type EmailVerificationConfig struct {
+	TokenTTL  int      `mapstructure:"token_ttl"`  // 验证链接有效期（小时），默认 24
+	VerifyURL string   `mapstructure:"verify_url"` // 验证链接地址，令牌作为 token 参数附加在后面
+	Restrict  []string `mapstructure:"restrict"`   // 邮箱未验证的用户禁止的操作
+}
+
+// PasswordResetConfig 找回密码配置
+type PasswordResetConfig struct {
+	TokenTTL int    `mapstructure:"token_ttl"` // 重置链接有效期（分钟），默认 30
+	ResetURL string `mapstructure:"reset_url"` // 前端重置密码页面地址，令牌作为 token 参数附加在后面
+}
+
+// LoginProtectionConfig 登录防暴力破解配置
+type LoginProtectionConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxAttempts   int  `mapstructure:"max_attempts"`    // 同一用户名在计数窗口内失败达到该次数后锁定账号
+	LockMinutes   int  `mapstructure:"lock_minutes"`    // 账号锁定时长（分钟）
+	IPMaxAttempts int  `mapstructure:"ip_max_attempts"` // 同一IP在计数窗口内失败达到该次数后暂停该IP登录
+	WindowMinutes int  `mapstructure:"window_minutes"`  // 失败计数窗口（分钟），从第一次失败开始计算
+	BaseDelayMS   int  `mapstructure:"base_delay_ms"`   // 失败响应的基础延迟（毫秒），每多失败一次翻倍
+	MaxDelayMS    int  `mapstructure:"max_delay_ms"`    // 失败响应的最大延迟（毫秒）
+}
+
+// LockDuration 账号锁定时长
+func (c *LoginProtectionConfig) LockDuration() time.Duration {
+	return time.Duration(c.LockMinutes) * time.Minute
+}
+
+// Window 失败计数窗口
+func (c *LoginProtectionConfig) Window() time.Duration {
+	return time.Duration(c.WindowMinutes) * time.Minute
+}
+
+// Delay 第 failures 次失败后的响应延迟，从基础延迟开始指数增长，不超过最大延迟
+func (c *LoginProtectionConfig) Delay(failures int) time.Duration {
+	if failures <= 0 || c.BaseDelayMS <= 0 {
+		return 0
+	}
+	limit := time.Duration(c.MaxDelayMS) * time.Millisecond
+	delay := time.Duration(c.BaseDelayMS) * time.Millisecond
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// TwoFactorConfig 两步验证配置
+type TwoFactorConfig struct {
+	Issuer          string `mapstructure:"issuer"`            // 验证器应用中显示的服务名称，为空时使用 app.name
+	RequireForAdmin bool   `mapstructure:"require_for_admin"` // 管理员必须启用两步验证才能访问管理接口
+}
+
+// OIDCConfig OpenID Connect 第三方登录配置
+type OIDCConfig struct {
+	StateTTL  int                  `mapstructure:"state_ttl"` // 授权请求有效期（分钟），默认 10
+	Providers []OIDCProviderConfig `mapstructure:"providers"`
+}
+
+// OIDCProviderConfig 身份提供方配置，支持发现文档的 OpenID Connect 提供方均可接入
+type OIDCProviderConfig struct {
+	Name         string   `mapstructure:"name"`         // 提供方标识，用于接口路径，只能包含小写字母、数字和连字符
+	DisplayName  string   `mapstructure:"display_name"` // 登录按钮上显示的名称，为空时使用 name
+	Issuer       string   `mapstructure:"issuer"`       // 签发者地址，端点和签名公钥从 {issuer}/.well-known/openid-configuration 获取
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"` // 公共客户端留空，仅依靠 PKCE
+	RedirectURL  string   `mapstructure:"redirect_url"`  // 前端回调页面，需在身份提供方登记
+	Scopes       []string `mapstructure:"scopes"`        // 为空时使用 openid、email、profile
+	AllowSignup  bool     `mapstructure:"allow_signup"`  // 未关联账号的用户首次登录时自动注册
+}
+
+// global 当前生效的配置，热更新时整体替换
+var global atomic.Pointer[Config]
+
+// Get 获取当前生效的配置
+// 返回的配置不可修改，热更新会替换为新的实例
+func Get() *Config {
+	return global.Load()
+}
+
+// Set 替换当前生效的配置，用于以代码方式提供配置（如测试）
+func Set(c *Config) {
+	global.Store(c)
+}
+
+// DefaultJWTSecret 示例配置中的JWT密钥，release 模式下禁止使用
+const DefaultJWTSecret = "your-secret-key-change-this-in-production"
 
 // LoadConfig 加载配置文件
-func LoadConfig(configPath string) (*Config, error) {
-	viper.SetConfigFile(configPath)
-	viper.SetConfigType("yaml")
+// 依次读取基础配置、环境覆盖文件（如 config.production.yaml）和 BLOG_ 前缀的环境变量，
+// 环境变量的 _FILE 变体从文件中读取值，适用于挂载的密钥文件
+func LoadConfig(configPath, env string) (*Config, error) {
+	config, err := load(configPath, env)
+	if err != nil {
+		return nil, err
+	}
+
+	global.Store(config)
+	return config, nil
+}
+
+// load 读取并校验配置，不修改当前生效的配置
+func load(configPath, env string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(configPath)
+	v.SetConfigType("yaml")
 
 	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
+	// 合并环境覆盖文件
+	if env != "" {
+		overlay := overlayPath(configPath, env)
+		if _, err := os.Stat(overlay); err == nil {
+			v.SetConfigFile(overlay)
+			if err := v.MergeInConfig(); err != nil {
+				return nil, fmt.Errorf("读取环境配置文件 %s 失败: %w", overlay, err)
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("读取环境配置文件 %s 失败: %w", overlay, err)
+		}
+	}
+
+	// 环境变量覆盖
+	if err := bindEnvs(v, reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, err
+	}
+
 	// 解析配置
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
+	if err := applyReplicaEnvs(&config.Database); err != nil {
+		return nil, err
+	}
+	if err := applyOIDCEnvs(&config.OIDC); err != nil {
+		return nil, err
+	}
+
+	// 校验配置
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
-	GlobalConfig = &config
 	return &config, nil
 }
 
+// overlayPath 获取环境覆盖文件路径，如 config/config.yaml -> config/config.production.yaml
+func overlayPath(configPath, env string) string {
+	ext := filepath.Ext(configPath)
+	return strings.TrimSuffix(configPath, ext) + "." + env + ext
+}
+
+// GetDriver 获取数据库驱动，未配置时默认 mysql
+func (c *DatabaseConfig) GetDriver() string {
+	if c.Driver == "" {
+		return DriverMySQL
+	}
+	return c.Driver
+}
+
 // GetDSN 获取数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
+	switch c.GetDriver() {
+	case DriverPostgres:
+		return c.postgresDSN()
+	case DriverSQLite:
+		return c.sqliteDSN()
+	default:
+		return c.mysqlDSN()
+	}
+}
+
+// mysqlDSN MySQL 连接字符串
+func (c *DatabaseConfig) mysqlDSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=Local",
 		c.Username,
 		c.Password,
@@ -110,6 +371,67 @@ func (c *DatabaseConfig) GetDSN() string {
 	)
 }
 
+// postgresDSN PostgreSQL 连接字符串
+func (c *DatabaseConfig) postgresDSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.Username, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     "/" + c.DBName,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// sqliteDSN SQLite 连接字符串，开启外键约束并设置忙等待超时
+func (c *DatabaseConfig) sqliteDSN() string {
+	return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", c.Path)
+}
+
+// GetReplicaDSN 获取副本连接字符串，副本未配置的字段沿用主库配置
+func (c *DatabaseConfig) GetReplicaDSN(r ReplicaConfig) string {
+	replica := *c
+	if r.Host != "" {
+		replica.Host = r.Host
+	}
+	if r.Port != 0 {
+		replica.Port = r.Port
+	}
+	if r.Username != "" {
+		replica.Username = r.Username
+	}
+	if r.Password != "" {
+		replica.Password = r.Password
+	}
+	return replica.GetDSN()
+}
+
+// GetStickyWindow 获取写入后读主库的时长
+func (c *DatabaseConfig) GetStickyWindow() time.Duration {
+	return time.Duration(c.StickyWindow) * time.Second
+}
+
+// GetName 获取数据库名称，用于监控指标标签
+func (c *DatabaseConfig) GetName() string {
+	if c.GetDriver() == DriverSQLite {
+		return filepath.Base(c.Path)
+	}
+	return c.DBName
+}
+
+// GetSlowThreshold 获取慢查询阈值，未配置时默认200毫秒
+func (c *DatabaseConfig) GetSlowThreshold() time.Duration {
+	if c.SlowThreshold <= 0 {
+		return 200 * time.Millisecond
+	}
+	return time.Duration(c.SlowThreshold) * time.Millisecond
+}
+
 // GetRedisAddr 获取Redis地址
 func (c *RedisConfig) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
@@ -124,3 +446,76 @@ func (c *JWTConfig) GetJWTExpireDuration() time.Duration {
 func (c *JWTConfig) GetRefreshExpireDuration() time.Duration {
 	return time.Duration(c.RefreshExpireHours) * time.Hour
 }
+
+// GetDriver 获取邮件驱动，未配置时默认 file
+func (c *MailConfig) GetDriver() string {
+	if c.Driver == "" {
+		return MailDriverFile
+	}
+	return c.Driver
+}
+
+// GetTokenTTL 获取邮箱验证链接有效期，未配置时默认24小时
+func (c *EmailVerificationConfig) GetTokenTTL() time.Duration {
+	if c.TokenTTL <= 0 {
+		return 24 * time.Hour
+	}
+	return time.Duration(c.TokenTTL) * time.Hour
+}
+
+// Restricts 判断邮箱未验证的用户是否禁止执行该操作
+func (c *EmailVerificationConfig) Restricts(action string) bool {
+	for _, a := range c.Restrict {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTokenTTL 获取重置链接有效期，未配置时默认30分钟
+func (c *PasswordResetConfig) GetTokenTTL() time.Duration {
+	if c.TokenTTL <= 0 {
+		return 30 * time.Minute
+	}
+	return time.Duration(c.TokenTTL) * time.Minute
+}
+
+// GetStateTTL 获取授权请求有效期，未配置时默认10分钟
+func (c *OIDCConfig) GetStateTTL() time.Duration {
+	if c.StateTTL <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.StateTTL) * time.Minute
+}
+
+// Provider 根据标识查找身份提供方
+func (c *OIDCConfig) Provider(name string) (*OIDCProviderConfig, bool) {
+	for i := range c.Providers {
+		if c.Providers[i].Name == name {
+			return &c.Providers[i], true
+		}
+	}
+	return nil, false
+}
+
+// GetDisplayName 获取显示名称，未配置时使用标识
+func (c *OIDCProviderConfig) GetDisplayName() string {
+	if c.DisplayName == "" {
+		return c.Name
+	}
+	return c.DisplayName
+}
+
+// GetScopes 获取申请的权限范围，始终包含 openid
+func (c *OIDCProviderConfig) GetScopes() []string {
+	if len(c.Scopes) == 0 {
+		return []string{"openid", "email", "profile"}
+	}
+	for _, scope := range c.Scopes {
+		if scope == "openid" {
+			return c.Scopes
+		}
+	}
+	return append([]string{"openid"}, c.Scopes...)
+}
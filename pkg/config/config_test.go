@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginProtectionDelay(t *testing.T) {
+	cfg := LoginProtectionConfig{BaseDelayMS: 100, MaxDelayMS: 1000}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{1000, time.Second},
+	}
+	for _, tt := range tests {
+		if got := cfg.Delay(tt.failures); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+
+	if got := (&LoginProtectionConfig{MaxDelayMS: 1000}).Delay(3); got != 0 {
+		t.Errorf("Delay() without base delay = %v, want 0", got)
+	}
+}
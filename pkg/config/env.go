@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "BLOG"
+
+// bindEnvs 按结构体的 mapstructure 标签绑定环境变量
+// 如 database.password 对应 BLOG_DATABASE_PASSWORD，
+// 设置 BLOG_DATABASE_PASSWORD_FILE 时从该文件读取值；
+// 切片中的字段无法按标签绑定，由 applyReplicaEnvs 和 applyOIDCEnvs 处理
+func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := bindEnvs(v, field.Type, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envName := EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if err := v.BindEnv(key, envName); err != nil {
+			return fmt.Errorf("绑定环境变量 %s 失败: %w", envName, err)
+		}
+
+		if value, ok, err := readEnvFile(envName); err != nil {
+			return err
+		} else if ok {
+			v.Set(key, value)
+		}
+	}
+	return nil
+}
+
+// readEnvFile 读取 name_FILE 指定的文件内容，未设置时返回 false
+func readEnvFile(name string) (string, bool, error) {
+	file := os.Getenv(name + "_FILE")
+	if file == "" {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", false, fmt.Errorf("读取 %s_FILE 指定的文件失败: %w", name, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+// lookupEnv 读取环境变量 name，设置 name_FILE 时从该文件读取
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok, err := readEnvFile(name); err != nil || ok {
+		return value, ok, err
+	}
+	value, ok := os.LookupEnv(name)
+	return value, ok, nil
+}
+
+// applyReplicaEnvs 切片中的字段无法按标签绑定环境变量，解析后按下标覆盖副本密码，
+// 如 database.replicas[0].password 对应 BLOG_DATABASE_REPLICAS_0_PASSWORD
+func applyReplicaEnvs(c *DatabaseConfig) error {
+	for i := range c.Replicas {
+		name := fmt.Sprintf("%s_DATABASE_REPLICAS_%d_PASSWORD", EnvPrefix, i)
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			c.Replicas[i].Password = value
+		}
+	}
+	return nil
+}
+
+// applyOIDCEnvs 解析后按提供方标识覆盖 client_secret，
+// 如 name 为 my-idp 的提供方对应 BLOG_OIDC_MY_IDP_CLIENT_SECRET
+func applyOIDCEnvs(c *OIDCConfig) error {
+	for i := range c.Providers {
+		p := &c.Providers[i]
+		name := EnvPrefix + "_OIDC_" + strings.ToUpper(strings.ReplaceAll(p.Name, "-", "_")) + "_CLIENT_SECRET"
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			return err
+		}
+		if ok {
+			p.ClientSecret = value
+		}
+	}
+	return nil
+}
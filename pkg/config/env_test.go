@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyReplicaEnvs(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "replica_password")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BLOG_DATABASE_REPLICAS_0_PASSWORD", "from-env")
+	t.Setenv("BLOG_DATABASE_REPLICAS_1_PASSWORD_FILE", secret)
+
+	cfg := DatabaseConfig{
+		Password: "primary",
+		Replicas: []ReplicaConfig{{Host: "a"}, {Host: "b"}, {Host: "c", Password: "from-config"}},
+	}
+	if err := applyReplicaEnvs(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"from-env", "from-file", "from-config"}
+	for i, r := range cfg.Replicas {
+		if r.Password != want[i] {
+			t.Errorf("replica %d password = %q, want %q", i, r.Password, want[i])
+		}
+	}
+
+	t.Setenv("BLOG_DATABASE_REPLICAS_2_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+	if err := applyReplicaEnvs(&cfg); err == nil {
+		t.Error("applyReplicaEnvs() with missing file succeeded")
+	}
+}
+
+func TestApplyOIDCEnvs(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "client_secret")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BLOG_OIDC_GOOGLE_CLIENT_SECRET", "from-env")
+	t.Setenv("BLOG_OIDC_MY_IDP_CLIENT_SECRET_FILE", secret)
+
+	cfg := OIDCConfig{Providers: []OIDCProviderConfig{
+		{Name: "google"},
+		{Name: "my-idp", ClientSecret: "from-config"},
+		{Name: "dex", ClientSecret: "from-config"},
+	}}
+	if err := applyOIDCEnvs(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"from-env", "from-file", "from-config"}
+	for i, p := range cfg.Providers {
+		if p.ClientSecret != want[i] {
+			t.Errorf("provider %q client_secret = %q, want %q", p.Name, p.ClientSecret, want[i])
+		}
+	}
+
+	t.Setenv("BLOG_OIDC_DEX_CLIENT_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+	if err := applyOIDCEnvs(&cfg); err == nil {
+		t.Error("applyOIDCEnvs() with missing file succeeded")
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/xiaoxin/blog-backend/pkg/i18n"
+)
+
+// oidcProviderName 身份提供方标识的格式
+var oidcProviderName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+
+// Validate 校验配置，返回所有不合法的配置项
+func (c *Config) Validate() error {
+	var errs []string
+
+	switch c.App.Mode {
+	case "debug", "release", "test":
+	default:
+		errs = append(errs, fmt.Sprintf("app.mode 只能是 debug、release 或 test，当前为 %q", c.App.Mode))
+	}
+	if c.App.Port <= 0 || c.App.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("app.port 必须在 1~65535 之间，当前为 %d", c.App.Port))
+	}
+	if c.App.Locale != "" && !i18n.IsSupported(c.App.Locale) {
+		errs = append(errs, fmt.Sprintf("app.locale 只能是 %s，当前为 %q", strings.Join(i18n.Supported(), "、"), c.App.Locale))
+	}
+	for _, proxy := range c.App.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			errs = append(errs, fmt.Sprintf("app.trusted_proxies 只能是 IP 或 CIDR，当前为 %q", proxy))
+		}
+	}
+
+	switch c.Database.GetDriver() {
+	case DriverMySQL, DriverPostgres:
+		if c.Database.Host == "" {
+			errs = append(errs, "database.host 不能为空")
+		}
+		if c.Database.DBName == "" {
+			errs = append(errs, "database.dbname 不能为空")
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			errs = append(errs, "database.driver 为 sqlite 时 database.path 不能为空")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("database.driver 只能是 mysql、postgres 或 sqlite，当前为 %q", c.Database.Driver))
+	}
+	if len(c.Database.Replicas) > 0 && c.Database.GetDriver() == DriverSQLite {
+		errs = append(errs, "sqlite 不支持配置 database.replicas")
+	}
+	switch c.Database.ReplicaPolicy {
+	case "", ReplicaPolicyRandom, ReplicaPolicyRoundRobin:
+	default:
+		errs = append(errs, fmt.Sprintf("database.replica_policy 只能是 random 或 round_robin，当前为 %q", c.Database.ReplicaPolicy))
+	}
+	if c.Database.StickyWindow < 0 {
+		errs = append(errs, "database.sticky_window 不能为负数")
+	}
+	if c.Database.AutoMigrate && c.App.Mode == "release" {
+		errs = append(errs, "release 模式下不能开启 database.auto_migrate，请使用版本化迁移")
+	}
+
+	if c.JWT.Secret == "" {
+		errs = append(errs, "jwt.secret 不能为空")
+	} else if c.App.Mode == "release" {
+		if c.JWT.Secret == DefaultJWTSecret {
+			errs = append(errs, "release 模式下不能使用默认的 jwt.secret")
+		} else if len(c.JWT.Secret) < 32 {
+			errs = append(errs, "release 模式下 jwt.secret 长度不能少于32个字符")
+		}
+	}
+	if c.JWT.ExpireHours <= 0 {
+		errs = append(errs, "jwt.expire_hours 必须大于0")
+	}
+	if c.JWT.RefreshExpireHours < c.JWT.ExpireHours {
+		errs = append(errs, "jwt.refresh_expire_hours 不能小于 jwt.expire_hours")
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Sprintf("log.level 只能是 debug、info、warn 或 error，当前为 %q", c.Log.Level))
+	}
+
+	if strings.TrimSpace(c.Upload.SavePath) == "" {
+		errs = append(errs, "upload.save_path 不能为空")
+	}
+	if c.Upload.MaxSize <= 0 {
+		errs = append(errs, "upload.max_size 必须大于0")
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.RequestsPerSecond <= 0 {
+			errs = append(errs, "rate_limit.requests_per_second 必须大于0")
+		}
+		if c.RateLimit.Burst <= 0 {
+			errs = append(errs, "rate_limit.burst 必须大于0")
+		}
+	}
+
+	if c.Tracing.Enabled && c.Tracing.Exporter == "otlp" && c.Tracing.Endpoint == "" {
+		errs = append(errs, "tracing.exporter 为 otlp 时 tracing.endpoint 不能为空")
+	}
+
+	switch c.Mail.GetDriver() {
+	case MailDriverSMTP:
+		if c.Mail.Host == "" || c.Mail.Port <= 0 {
+			errs = append(errs, "mail.driver 为 smtp 时 mail.host 和 mail.port 不能为空")
+		}
+	case MailDriverFile:
+		if c.Mail.OutboxDir == "" {
+			errs = append(errs, "mail.driver 为 file 时 mail.outbox_dir 不能为空")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("mail.driver 只能是 smtp 或 file，当前为 %q", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, "mail.from 不能为空")
+	}
+
+	if c.EmailVerification.VerifyURL == "" {
+		errs = append(errs, "email_verification.verify_url 不能为空")
+	}
+	for _, action := range c.EmailVerification.Restrict {
+		switch action {
+		case ActionArticle, ActionLike, ActionUpload:
+		default:
+			errs = append(errs, fmt.Sprintf("email_verification.restrict 只能包含 article、like 或 upload，当前为 %q", action))
+		}
+	}
+
+	if c.PasswordReset.ResetURL == "" {
+		errs = append(errs, "password_reset.reset_url 不能为空")
+	}
+
+	if c.LoginProtection.Enabled {
+		lp := c.LoginProtection
+		if lp.MaxAttempts <= 0 || lp.IPMaxAttempts <= 0 {
+			errs = append(errs, "login_protection.max_attempts 和 login_protection.ip_max_attempts 必须大于0")
+		}
+		if lp.LockMinutes <= 0 || lp.WindowMinutes <= 0 {
+			errs = append(errs, "login_protection.lock_minutes 和 login_protection.window_minutes 必须大于0")
+		}
+		if lp.BaseDelayMS < 0 || lp.MaxDelayMS < lp.BaseDelayMS {
+			errs = append(errs, "login_protection.base_delay_ms 不能小于0，且不能大于 login_protection.max_delay_ms")
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, p := range c.OIDC.Providers {
+		if !oidcProviderName.MatchString(p.Name) {
+			errs = append(errs, fmt.Sprintf("oidc.providers[%d].name 只能包含小写字母、数字和连字符，当前为 %q", i, p.Name))
+		} else if seen[p.Name] {
+			errs = append(errs, fmt.Sprintf("oidc.providers[%d].name 重复: %q", i, p.Name))
+		}
+		seen[p.Name] = true
+		if p.Issuer == "" || p.ClientID == "" || p.RedirectURL == "" {
+			errs = append(errs, fmt.Sprintf("oidc.providers[%d] 的 issuer、client_id 和 redirect_url 不能为空", i))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New("配置校验失败:\n  - " + strings.Join(errs, "\n  - "))
+	}
+	return nil
+}
+
+// isIPOrCIDR 判断是否为 IP 地址或 CIDR 网段
+func isIPOrCIDR(s string) bool {
+	if net.ParseIP(s) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
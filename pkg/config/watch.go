@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ReloadHandler 配置热更新回调
+// changes 为已生效的变更，ignored 为需要重启才能生效而被忽略的变更
+type ReloadHandler func(old, new *Config, changes, ignored []string)
+
+// Watch 监听配置文件变化并热更新可在运行时修改的配置：
+// 日志级别、上传限制（保存目录除外）、跨域来源、限流参数、邮箱验证、找回密码、登录保护、两步验证和第三方登录配置。
+// 新配置校验失败时保留旧配置并通过 onError 回调报告
+func Watch(configPath, env string, onReload ReloadHandler, onError func(error)) {
+	var mu sync.Mutex
+	reload := func(fsnotify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		next, err := load(configPath, env)
+		if err != nil {
+			onError(fmt.Errorf("配置热更新被拒绝，继续使用旧配置: %w", err))
+			return
+		}
+
+		cur := Get()
+		merged := *cur
+		merged.Log.Level = next.Log.Level
+		// 保存目录与静态文件路由绑定，只热更新大小和类型限制
+		merged.Upload = next.Upload
+		merged.Upload.SavePath = cur.Upload.SavePath
+		merged.CORS = next.CORS
+		merged.RateLimit = next.RateLimit
+		merged.EmailVerification = next.EmailVerification
+		merged.PasswordReset = next.PasswordReset
+		merged.LoginProtection = next.LoginProtection
+		merged.TwoFactor = next.TwoFactor
+		merged.OIDC = next.OIDC
+
+		changes := Diff(cur, &merged)
+		ignored := Diff(&merged, next)
+		if len(changes) == 0 && len(ignored) == 0 {
+			return
+		}
+
+		global.Store(&merged)
+		onReload(cur, &merged, changes, ignored)
+	}
+
+	files := []string{configPath}
+	if env != "" {
+		if overlay := overlayPath(configPath, env); fileExists(overlay) {
+			files = append(files, overlay)
+		}
+	}
+	for _, file := range files {
+		v := viper.New()
+		v.SetConfigFile(file)
+		v.SetConfigType("yaml")
+		v.OnConfigChange(reload)
+		v.WatchConfig()
+	}
+}
+
+// Diff 比较两份配置，返回形如 "log.level: debug -> info" 的变更列表，敏感字段不输出具体值
+func Diff(old, new *Config) []string {
+	var changes []string
+	diffValue(reflect.ValueOf(*old), reflect.ValueOf(*new), "", &changes)
+	return changes
+}
+
+func diffValue(a, b reflect.Value, prefix string, changes *[]string) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		av, bv := a.Field(i), b.Field(i)
+		if field.Type.Kind() == reflect.Struct {
+			diffValue(av, bv, key, changes)
+			continue
+		}
+		if reflect.DeepEqual(av.Interface(), bv.Interface()) {
+			continue
+		}
+
+		// 结构体列表（如数据库副本、身份提供方）可能包含密码和密钥，不输出具体值
+		if isSecretKey(key) || isStructList(field.Type) {
+			*changes = append(*changes, key+": ****** -> ******")
+		} else {
+			*changes = append(*changes, fmt.Sprintf("%s: %v -> %v", key, av.Interface(), bv.Interface()))
+		}
+	}
+}
+
+func isSecretKey(key string) bool {
+	return strings.HasSuffix(key, "password") || strings.HasSuffix(key, "secret")
+}
+
+func isStructList(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Map) && t.Elem().Kind() == reflect.Struct
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,69 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testCursor struct {
+	Sort string `json:"s"`
+	ID   uint   `json:"i"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	Init("secret")
+
+	s, err := Encode(testCursor{Sort: "-views", ID: 42})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var got testCursor
+	if err := Decode(s, &got); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got != (testCursor{Sort: "-views", ID: 42}) {
+		t.Errorf("Decode() = %+v", got)
+	}
+}
+
+func TestDecodeRejectsInvalid(t *testing.T) {
+	Init("secret")
+	valid, err := Encode(testCursor{ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, sig, _ := strings.Cut(valid, ".")
+
+	// 修改内容但保留原签名
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"","i":43}`)) + "." + sig
+
+	Init("other-secret")
+	otherKey, err := Encode(testCursor{ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Init("secret")
+
+	tests := []struct {
+		name string
+		s    string
+	}{
+		{"empty", ""},
+		{"no signature", data},
+		{"tampered payload", tampered},
+		{"tampered signature", data + "." + base64.RawURLEncoding.EncodeToString([]byte("forged"))},
+		{"other key", otherKey},
+		{"bad encoding", "!!!." + sig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testCursor
+			if err := Decode(tt.s, &got); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode() error = %v, want %v", err, ErrInvalid)
+			}
+		})
+	}
+}
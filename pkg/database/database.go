@@ -0,0 +1,155 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/logger"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
+	"github.com/xiaoxin/blog-backend/pkg/tracing"
+)
+
+var DB *gorm.DB
+
+// InitDB 初始化数据库连接
+func InitDB(cfg *config.DatabaseConfig) error {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return err
+	}
+
+	// 设置日志级别
+	var logLevel gormlogger.LogLevel
+	switch cfg.LogLevel {
+	case 1:
+		logLevel = gormlogger.Silent
+	case 2:
+		logLevel = gormlogger.Error
+	case 3:
+		logLevel = gormlogger.Warn
+	case 4:
+		logLevel = gormlogger.Info
+	default:
+		logLevel = gormlogger.Info
+	}
+
+	// release 模式下不输出SQL参数值
+	redact := config.Get() != nil && config.Get().App.Mode == "release"
+	gormLogger := logger.NewGormLogger(logLevel, cfg.GetSlowThreshold(), redact)
+
+	// 创建数据库连接
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormLogger,
+		// 将各数据库的唯一约束错误统一转换为 gorm.ErrDuplicatedKey
+		TranslateError: true,
+		NowFunc: func() time.Time {
+			return time.Now().Local()
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	// 注册查询指标回调
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return fmt.Errorf("注册数据库指标插件失败: %w", err)
+	}
+
+	// 注册链路追踪回调
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return fmt.Errorf("注册数据库追踪插件失败: %w", err)
+	}
+
+	// 注册只读副本
+	if err := registerReplicas(db, cfg); err != nil {
+		return err
+	}
+
+	// 获取底层的 sql.DB
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("获取数据库实例失败: %w", err)
+	}
+
+	// 设置连接池参数
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+
+	// 测试连接
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("数据库连接测试失败: %w", err)
+	}
+
+	// 注册连接池指标
+	if err := metrics.RegisterDBStats(sqlDB, cfg.GetName()); err != nil {
+		return fmt.Errorf("注册连接池指标失败: %w", err)
+	}
+
+	DB = db
+	logger.Info("数据库连接成功")
+	return nil
+}
+
+// newDialector 根据配置的驱动创建 GORM Dialector
+func newDialector(cfg *config.DatabaseConfig) (gorm.Dialector, error) {
+	if cfg.GetDriver() == config.DriverSQLite {
+		// SQLite 驱动依赖 CGO，需以 CGO_ENABLED=1 编译
+		if dir := filepath.Dir(cfg.Path); dir != "." {
+			if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+				return nil, fmt.Errorf("创建SQLite数据目录失败: %w", err)
+			}
+		}
+	}
+
+	return openDialector(cfg.GetDriver(), cfg.GetDSN())
+}
+
+// openDialector 按驱动名称和连接字符串创建 GORM Dialector
+func openDialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case config.DriverMySQL:
+		return mysql.Open(dsn), nil
+	case config.DriverPostgres:
+		return postgres.Open(dsn), nil
+	case config.DriverSQLite:
+		return sqlite.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+}
+
+// GetDB 获取数据库实例
+func GetDB() *gorm.DB {
+	return DB
+}
+
+// CloseDB 关闭数据库连接
+func CloseDB() error {
+	if DB != nil {
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}
+	return nil
+}
+
+// AutoMigrate 自动迁移数据表
+// 仅用于开发环境快速同步模型结构，生产环境请使用版本化迁移 Migrator
+func AutoMigrate(models ...interface{}) error {
+	if DB == nil {
+		return fmt.Errorf("数据库未初始化")
+	}
+	return DB.AutoMigrate(models...)
+}
@@ -0,0 +1,385 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFS embed.FS
+
+// migrationLockName 迁移使用的数据库锁名称
+const migrationLockName = "blog_schema_migrations"
+
+// migrationLockTimeout 等待其他实例完成迁移的最长时间（秒）
+const migrationLockTimeout = 300
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration 版本化迁移
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName 指定表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator 创建迁移执行器，加载当前数据库驱动对应的内置迁移文件
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	if db == nil {
+		return nil, fmt.Errorf("数据库未初始化")
+	}
+
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations 读取内置迁移文件
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的数据库迁移类型 %s: %w", dialect, err)
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		m := migrationFileRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件 %s 版本号无效: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(migrationFS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件 %s 失败: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[uint(version)]
+		if !ok {
+			mig = &Migration{Version: uint(version), Name: m[2]}
+			byVersion[uint(version)] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("迁移版本 %d 存在多个名称: %s, %s", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少 up 文件", mig.Version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Latest 最新的迁移版本
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up 执行所有未执行的迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down 回滚最近的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("回滚步数必须大于0")
+	}
+
+	return m.withLock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.runDown(ctx, mig); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// To 迁移到指定版本，高于当前版本时执行 up，低于当前版本时执行 down
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && !m.hasVersion(version) {
+		return fmt.Errorf("迁移版本 %d 不存在", version)
+	}
+
+	return m.withLock(ctx, func() error {
+		applied, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		// 回滚高于目标版本的迁移
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version <= version {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				if err := m.runDown(ctx, mig); err != nil {
+					return err
+				}
+			}
+		}
+
+		// 执行不高于目标版本的迁移
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if _, ok := applied[mig.Version]; !ok {
+				if err := m.runUp(ctx, mig); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status 获取所有迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if rec, ok := applied[mig.Version]; ok {
+			appliedAt := rec.AppliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
+
+func (m *Migrator) hasVersion(version uint) bool {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	// 迁移相关的查询必须读主库，副本可能存在复制延迟
+	if err := m.db.WithContext(WithPrimary(ctx)).AutoMigrate(&SchemaMigration{}); err != nil {
+		return fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[uint]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := m.db.WithContext(WithPrimary(ctx)).Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	applied := make(map[uint]SchemaMigration, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+func (m *Migrator) runUp(ctx context.Context, mig Migration) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := execScript(tx, mig.Up); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{
+			Version:   mig.Version,
+			Name:      mig.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("执行迁移 %04d_%s 失败: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) runDown(ctx context.Context, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("迁移 %04d_%s 不支持回滚", mig.Version, mig.Name)
+	}
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := execScript(tx, mig.Down); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, mig.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("回滚迁移 %04d_%s 失败: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// execScript 逐条执行迁移脚本中的SQL语句
+// 语句以行尾分号分隔，忽略 -- 开头的注释行
+func execScript(tx *gorm.DB, script string) error {
+	var stmt strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		stmt.WriteString(line)
+		stmt.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			if err := tx.Exec(stmt.String()).Error; err != nil {
+				return err
+			}
+			stmt.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(stmt.String()); rest != "" {
+		return tx.Exec(rest).Error
+	}
+	return nil
+}
+
+// withLock 在数据库锁保护下执行迁移，避免多个实例同时迁移
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+	return fn()
+}
+
+// lock 获取迁移锁，返回解锁函数
+// MySQL 和 PostgreSQL 的锁与会话绑定，需要在同一个连接上加锁和解锁；
+// SQLite 为单机文件数据库，由写锁保证串行，无需额外加锁
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	dialect := m.db.Dialector.Name()
+	if dialect == "sqlite" {
+		return func() {}, nil
+	}
+
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+
+	switch dialect {
+	case "mysql":
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&got); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if !got.Valid || got.Int64 != 1 {
+			conn.Close()
+			return nil, errors.New("获取迁移锁超时，可能有其他实例正在迁移")
+		}
+		return func() {
+			_, _ = conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", migrationLockName)
+			conn.Close()
+		}, nil
+	case "postgres":
+		lockCtx, cancel := context.WithTimeout(ctx, migrationLockTimeout*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock(hashtext($1))", migrationLockName); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		return func() {
+			_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", migrationLockName)
+			conn.Close()
+		}, nil
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("不支持的数据库类型: %s", dialect)
+	}
+}
@@ -0,0 +1,95 @@
+-- 初始表结构，与此前 AutoMigrate 生成的结构一致
+-- 使用 IF NOT EXISTS 以便已由 AutoMigrate 建表的库直接接入版本化迁移
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `username` varchar(50) NOT NULL,
+  `password` varchar(255) NOT NULL,
+  `email` varchar(100),
+  `nickname` varchar(50),
+  `avatar` varchar(255),
+  `role` varchar(20) DEFAULT 'user',
+  `status` bigint DEFAULT 1,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_users_username` (`username`),
+  UNIQUE INDEX `idx_users_email` (`email`),
+  INDEX `idx_users_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `categories` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(50) NOT NULL,
+  `description` varchar(255),
+  `sort` bigint DEFAULT 0,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_categories_name` (`name`),
+  INDEX `idx_categories_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `tags` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `name` varchar(50) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_tags_name` (`name`),
+  INDEX `idx_tags_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `articles` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `title` varchar(200) NOT NULL,
+  `description` varchar(500),
+  `content` longtext NOT NULL,
+  `cover` varchar(255),
+  `author_id` bigint unsigned NOT NULL,
+  `category_id` bigint unsigned,
+  `view_count` bigint DEFAULT 0,
+  `like_count` bigint DEFAULT 0,
+  `status` bigint DEFAULT 1,
+  `is_top` boolean DEFAULT false,
+  PRIMARY KEY (`id`),
+  INDEX `idx_articles_deleted_at` (`deleted_at`),
+  INDEX `idx_articles_author_id` (`author_id`),
+  INDEX `idx_articles_category_id` (`category_id`),
+  INDEX `idx_articles_status` (`status`),
+  CONSTRAINT `fk_users_articles` FOREIGN KEY (`author_id`) REFERENCES `users` (`id`),
+  CONSTRAINT `fk_categories_articles` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `article_tags` (
+  `article_id` bigint unsigned NOT NULL,
+  `tag_id` bigint unsigned NOT NULL,
+  PRIMARY KEY (`article_id`, `tag_id`),
+  CONSTRAINT `fk_article_tags_article` FOREIGN KEY (`article_id`) REFERENCES `articles` (`id`),
+  CONSTRAINT `fk_article_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `comments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `article_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `content` text NOT NULL,
+  `parent_id` bigint unsigned,
+  `status` bigint DEFAULT 1,
+  PRIMARY KEY (`id`),
+  INDEX `idx_comments_deleted_at` (`deleted_at`),
+  INDEX `idx_comments_article_id` (`article_id`),
+  INDEX `idx_comments_user_id` (`user_id`),
+  INDEX `idx_comments_parent_id` (`parent_id`),
+  CONSTRAINT `fk_articles_comments` FOREIGN KEY (`article_id`) REFERENCES `articles` (`id`),
+  CONSTRAINT `fk_users_comments` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+-- 用户封禁原因、封禁到期时间和令牌版本
+-- banned_until 为空且 status 为 0 表示永久封禁；token_version 递增后旧令牌失效，用于强制下线
+
+ALTER TABLE `users` ADD COLUMN `ban_reason` varchar(255) NOT NULL DEFAULT '' AFTER `status`;
+ALTER TABLE `users` ADD COLUMN `banned_until` datetime(3) NULL AFTER `ban_reason`;
+ALTER TABLE `users` ADD COLUMN `token_version` bigint NOT NULL DEFAULT 0 AFTER `banned_until`;
@@ -0,0 +1,7 @@
+-- 邮箱验证时间和待验证的新邮箱
+-- 已有用户视为已验证邮箱；未填写的邮箱改为 NULL，避免多个空邮箱违反唯一索引
+
+ALTER TABLE `users` ADD COLUMN `email_verified_at` datetime(3) NULL AFTER `email`;
+ALTER TABLE `users` ADD COLUMN `pending_email` varchar(100) NOT NULL DEFAULT '' AFTER `email_verified_at`;
+UPDATE `users` SET `email` = NULL WHERE `email` = '';
+UPDATE `users` SET `email_verified_at` = `created_at` WHERE `email` IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `user_recovery_codes`;
+ALTER TABLE `users` DROP COLUMN `totp_last_step`;
+ALTER TABLE `users` DROP COLUMN `totp_enabled_at`;
+ALTER TABLE `users` DROP COLUMN `totp_secret`;
@@ -0,0 +1,16 @@
+-- 两步验证密钥和恢复码
+
+ALTER TABLE `users` ADD COLUMN `totp_secret` varchar(64) NOT NULL DEFAULT '' AFTER `token_version`;
+ALTER TABLE `users` ADD COLUMN `totp_enabled_at` datetime(3) NULL AFTER `totp_secret`;
+ALTER TABLE `users` ADD COLUMN `totp_last_step` bigint NOT NULL DEFAULT 0 AFTER `totp_enabled_at`;
+
+CREATE TABLE IF NOT EXISTS `user_recovery_codes` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `code_hash` varchar(64) NOT NULL,
+  `used_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_recovery_codes_user_id` (`user_id`),
+  CONSTRAINT `fk_user_recovery_codes_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
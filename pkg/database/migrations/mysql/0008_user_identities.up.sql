@@ -0,0 +1,14 @@
+-- 第三方登录身份
+
+CREATE TABLE IF NOT EXISTS `user_identities` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime(3) NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `provider` varchar(50) NOT NULL,
+  `subject` varchar(255) NOT NULL,
+  `email` varchar(100) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_user_identities_provider_subject` (`provider`, `subject`),
+  UNIQUE INDEX `idx_user_identities_user_provider` (`user_id`, `provider`),
+  CONSTRAINT `fk_user_identities_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,89 @@
+-- 初始表结构
+
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "username" varchar(50) NOT NULL,
+  "password" varchar(255) NOT NULL,
+  "email" varchar(100),
+  "nickname" varchar(50),
+  "avatar" varchar(255),
+  "role" varchar(20) DEFAULT 'user',
+  "status" bigint DEFAULT 1
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_username" ON "users" ("username");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "name" varchar(50) NOT NULL,
+  "description" varchar(255),
+  "sort" bigint DEFAULT 0
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_categories_name" ON "categories" ("name");
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "tags" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "name" varchar(50) NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_name" ON "tags" ("name");
+CREATE INDEX IF NOT EXISTS "idx_tags_deleted_at" ON "tags" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "articles" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "title" varchar(200) NOT NULL,
+  "description" varchar(500),
+  "content" text NOT NULL,
+  "cover" varchar(255),
+  "author_id" bigint NOT NULL,
+  "category_id" bigint,
+  "view_count" bigint DEFAULT 0,
+  "like_count" bigint DEFAULT 0,
+  "status" bigint DEFAULT 1,
+  "is_top" boolean DEFAULT false,
+  CONSTRAINT "fk_users_articles" FOREIGN KEY ("author_id") REFERENCES "users" ("id"),
+  CONSTRAINT "fk_categories_articles" FOREIGN KEY ("category_id") REFERENCES "categories" ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_articles_deleted_at" ON "articles" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_articles_author_id" ON "articles" ("author_id");
+CREATE INDEX IF NOT EXISTS "idx_articles_category_id" ON "articles" ("category_id");
+CREATE INDEX IF NOT EXISTS "idx_articles_status" ON "articles" ("status");
+
+CREATE TABLE IF NOT EXISTS "article_tags" (
+  "article_id" bigint NOT NULL,
+  "tag_id" bigint NOT NULL,
+  PRIMARY KEY ("article_id", "tag_id"),
+  CONSTRAINT "fk_article_tags_article" FOREIGN KEY ("article_id") REFERENCES "articles" ("id"),
+  CONSTRAINT "fk_article_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags" ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "comments" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "updated_at" timestamptz,
+  "deleted_at" timestamptz,
+  "article_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "content" text NOT NULL,
+  "parent_id" bigint,
+  "status" bigint DEFAULT 1,
+  CONSTRAINT "fk_articles_comments" FOREIGN KEY ("article_id") REFERENCES "articles" ("id"),
+  CONSTRAINT "fk_users_comments" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_comments_deleted_at" ON "comments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_comments_article_id" ON "comments" ("article_id");
+CREATE INDEX IF NOT EXISTS "idx_comments_user_id" ON "comments" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_comments_parent_id" ON "comments" ("parent_id");
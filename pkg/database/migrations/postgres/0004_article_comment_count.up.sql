@@ -0,0 +1,8 @@
+-- 文章评论数，用于按评论数排序，已有文章按未删除的评论回填
+
+ALTER TABLE "articles" ADD COLUMN "comment_count" bigint DEFAULT 0;
+
+UPDATE "articles" SET "comment_count" = (
+  SELECT COUNT(*) FROM "comments"
+  WHERE "comments"."article_id" = "articles"."id" AND "comments"."deleted_at" IS NULL
+);
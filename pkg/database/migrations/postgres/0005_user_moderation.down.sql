@@ -0,0 +1,3 @@
+ALTER TABLE "users" DROP COLUMN "token_version";
+ALTER TABLE "users" DROP COLUMN "banned_until";
+ALTER TABLE "users" DROP COLUMN "ban_reason";
@@ -0,0 +1,13 @@
+-- 第三方登录身份
+
+CREATE TABLE IF NOT EXISTS "user_identities" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz,
+  "user_id" bigint NOT NULL,
+  "provider" varchar(50) NOT NULL,
+  "subject" varchar(255) NOT NULL,
+  "email" varchar(100) NOT NULL DEFAULT '',
+  CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_provider_subject" ON "user_identities" ("provider", "subject");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_user_provider" ON "user_identities" ("user_id", "provider");
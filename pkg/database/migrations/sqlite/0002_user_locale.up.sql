@@ -0,0 +1,3 @@
+-- 用户语言偏好，为空表示按请求的 Accept-Language 选择
+
+ALTER TABLE `users` ADD COLUMN `locale` varchar(10) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- 文章列表游标分页按 (is_top, created_at, id) 排序和定位
+
+CREATE INDEX IF NOT EXISTS `idx_articles_list` ON `articles` (`is_top`, `created_at`, `id`);
@@ -0,0 +1,3 @@
+-- 需要 SQLite 3.35 及以上版本
+
+ALTER TABLE `articles` DROP COLUMN `comment_count`;
@@ -0,0 +1,5 @@
+-- 需要 SQLite 3.35 及以上版本
+
+ALTER TABLE `users` DROP COLUMN `token_version`;
+ALTER TABLE `users` DROP COLUMN `banned_until`;
+ALTER TABLE `users` DROP COLUMN `ban_reason`;
@@ -0,0 +1,4 @@
+-- 需要 SQLite 3.35 及以上版本
+
+ALTER TABLE `users` DROP COLUMN `pending_email`;
+ALTER TABLE `users` DROP COLUMN `email_verified_at`;
@@ -0,0 +1,6 @@
+-- 需要 SQLite 3.35 及以上版本
+
+DROP TABLE IF EXISTS `user_recovery_codes`;
+ALTER TABLE `users` DROP COLUMN `totp_last_step`;
+ALTER TABLE `users` DROP COLUMN `totp_enabled_at`;
+ALTER TABLE `users` DROP COLUMN `totp_secret`;
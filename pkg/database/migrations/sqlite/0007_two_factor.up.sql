@@ -0,0 +1,15 @@
+-- 两步验证密钥和恢复码
+
+ALTER TABLE `users` ADD COLUMN `totp_secret` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE `users` ADD COLUMN `totp_enabled_at` datetime;
+ALTER TABLE `users` ADD COLUMN `totp_last_step` integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS `user_recovery_codes` (
+  `id` integer PRIMARY KEY AUTOINCREMENT,
+  `created_at` datetime,
+  `user_id` integer NOT NULL,
+  `code_hash` varchar(64) NOT NULL,
+  `used_at` datetime,
+  CONSTRAINT `fk_user_recovery_codes_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_user_recovery_codes_user_id` ON `user_recovery_codes` (`user_id`);
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// primaryKey 强制读主库标记在 context 中的键
+type primaryKey struct{}
+
+// WithPrimary 标记后续查询读主库，用于写入后需要立即读到最新数据的场景
+// 未配置副本时不产生任何影响
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary 判断 context 是否要求读主库
+func UsePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// registerReplicas 注册只读副本，查询走副本，写入和事务内的查询走主库
+func registerReplicas(db *gorm.DB, cfg *config.DatabaseConfig) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for _, r := range cfg.Replicas {
+		dialector, err := openDialector(cfg.GetDriver(), cfg.GetReplicaDSN(r))
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	var policy dbresolver.Policy = dbresolver.RandomPolicy{}
+	if cfg.ReplicaPolicy == config.ReplicaPolicyRoundRobin {
+		policy = dbresolver.RoundRobinPolicy()
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   policy,
+	}).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("注册只读副本失败: %w", err)
+	}
+
+	// WithPrimary 标记的查询切回主库
+	if err := db.Callback().Query().Before("gorm:query").Register("blog:use_primary", usePrimary); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("blog:use_primary", usePrimary); err != nil {
+		return err
+	}
+	return db.Callback().Raw().Before("gorm:raw").Register("blog:use_primary", usePrimary)
+}
+
+// usePrimary 查询前检查 context，需要时切换到主库
+func usePrimary(db *gorm.DB) {
+	if UsePrimary(db.Statement.Context) {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
@@ -0,0 +1,28 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// MaxTxAttempts 事务遇到可重试错误时的最大执行次数
+const MaxTxAttempts = 3
+
+// IsRetryable 判断错误是否为可重试的瞬时错误（死锁、锁等待超时、序列化失败）
+func IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// 1213: 死锁，1205: 锁等待超时
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40001: 序列化失败，40P01: 死锁
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+// Package i18n 多语言消息
+//
+// 消息目录位于 locales/<语言>.json，以消息ID为键，值为 fmt 格式的模板。
+// 找不到消息时依次回退到默认语言和消息ID本身。
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/text/language"
+)
+
+// 支持的语言
+const (
+	LocaleZhCN = "zh-CN"
+	LocaleEn   = "en"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+var (
+	// supported 支持的语言，顺序与 matcher 一致
+	supported = []string{LocaleZhCN, LocaleEn}
+	matcher   = language.NewMatcher([]language.Tag{
+		language.MustParse(LocaleZhCN),
+		language.MustParse(LocaleEn),
+	})
+
+	catalogs      = mustLoadCatalogs()
+	defaultLocale atomic.Value
+)
+
+func init() {
+	defaultLocale.Store(LocaleZhCN)
+}
+
+// mustLoadCatalogs 加载内嵌的消息目录
+func mustLoadCatalogs() map[string]map[string]string {
+	result := make(map[string]map[string]string, len(supported))
+	for _, locale := range supported {
+		data, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("读取消息目录 %s 失败: %v", locale, err))
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("解析消息目录 %s 失败: %v", locale, err))
+		}
+		result[locale] = messages
+	}
+	return result
+}
+
+// Supported 获取支持的语言列表
+func Supported() []string {
+	return append([]string(nil), supported...)
+}
+
+// IsSupported 判断语言是否受支持
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// SetDefault 设置默认语言
+func SetDefault(locale string) error {
+	if !IsSupported(locale) {
+		return fmt.Errorf("不支持的语言: %s，可选值: %s", locale, strings.Join(supported, ", "))
+	}
+	defaultLocale.Store(locale)
+	return nil
+}
+
+// Default 获取默认语言
+func Default() string {
+	return defaultLocale.Load().(string)
+}
+
+// Match 根据 Accept-Language 选择最合适的语言，没有匹配时返回默认语言
+func Match(acceptLanguage string) string {
+	if locale, ok := match(acceptLanguage); ok {
+		return locale
+	}
+	return Default()
+}
+
+// Normalize 将 en-US、zh 等语言标签规范化为支持的语言
+func Normalize(tag string) (string, bool) {
+	return match(tag)
+}
+
+func match(acceptLanguage string) (string, bool) {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return supported[index], true
+}
+
+// Lookup 查找消息模板，找不到时回退到默认语言
+func Lookup(locale, id string) (string, bool) {
+	if msg, ok := catalogs[locale][id]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default()][id]
+	return msg, ok
+}
+
+// T 翻译消息，args 按 fmt 格式填充模板；找不到消息时返回消息ID本身
+func T(locale, id string, args ...interface{}) string {
+	msg, ok := Lookup(locale, id)
+	if !ok {
+		msg = id
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// localeKey 语言在 context 中的键
+type localeKey struct{}
+
+// WithLocale 将语言放入 context
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext 获取 context 中的语言，未设置时返回默认语言
+func FromContext(ctx context.Context) string {
+	if ctx != nil {
+		if locale, ok := ctx.Value(localeKey{}).(string); ok {
+			return locale
+		}
+	}
+	return Default()
+}
@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", LocaleZhCN},
+		{"en", LocaleEn},
+		{"en-US,en;q=0.9", LocaleEn},
+		{"en-GB", LocaleEn},
+		{"zh", LocaleZhCN},
+		{"zh-TW", LocaleZhCN},
+		{"fr-FR, en;q=0.5", LocaleEn},
+		{"zh-CN;q=0.3, en;q=0.8", LocaleEn},
+		{"fr", LocaleZhCN},
+		{"not a language tag!!", LocaleZhCN},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.acceptLanguage); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{"en", LocaleEn, true},
+		{"en-US", LocaleEn, true},
+		{"zh", LocaleZhCN, true},
+		{"zh-CN", LocaleZhCN, true},
+		{"fr", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.tag)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Normalize(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		id     string
+		args   []interface{}
+		want   string
+	}{
+		{"english", LocaleEn, "not_found", nil, "Resource not found"},
+		{"chinese", LocaleZhCN, "not_found", nil, "资源不存在"},
+		{"unsupported locale uses default", "fr", "not_found", nil, "资源不存在"},
+		{"unknown id", LocaleEn, "no_such_message", nil, "no_such_message"},
+		{"template", LocaleEn, "query_not_allowed", []interface{}{"sort", "title", "id, views"}, `sort does not support "title", allowed values: id, views`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := T(tt.locale, tt.id, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	t.Cleanup(func() { _ = SetDefault(LocaleZhCN) })
+
+	if err := SetDefault("fr"); err == nil {
+		t.Error("SetDefault(fr) succeeded")
+	}
+	if err := SetDefault(LocaleEn); err != nil {
+		t.Fatal(err)
+	}
+	if got := Match("fr"); got != LocaleEn {
+		t.Errorf("Match() with default en = %q", got)
+	}
+	if got := FromContext(context.Background()); got != LocaleEn {
+		t.Errorf("FromContext() without locale = %q", got)
+	}
+	if got := FromContext(WithLocale(context.Background(), LocaleZhCN)); got != LocaleZhCN {
+		t.Errorf("FromContext() = %q", got)
+	}
+}
+
+func TestCatalogsMatch(t *testing.T) {
+	// 各语言的消息目录包含相同的消息ID和相同数量的模板参数
+	base := catalogs[LocaleZhCN]
+	for _, locale := range supported {
+		for id, msg := range catalogs[locale] {
+			other, ok := base[id]
+			if !ok {
+				t.Errorf("%s: %q missing from %s", locale, id, LocaleZhCN)
+				continue
+			}
+			if strings.Count(msg, "%") != strings.Count(other, "%") {
+				t.Errorf("%s: %q has different parameters: %q vs %q", locale, id, msg, other)
+			}
+		}
+		if len(catalogs[locale]) != len(base) {
+			t.Errorf("%s has %d messages, %s has %d", locale, len(catalogs[locale]), LocaleZhCN, len(base))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 操作令牌的用途
+const (
+	PurposeVerifyEmail = "verify_email"
+	PurposeMFALogin    = "mfa_login"
+)
+
+// ActionClaims 操作令牌（如邮箱验证链接）的声明
+type ActionClaims struct {
+	UserID uint   `json:"user_id"`
+	Value  string `json:"value,omitempty"` // 令牌绑定的值，如待验证的邮箱、登录时的令牌版本
+	jwt.RegisteredClaims
+}
+
+// GenerateActionToken 生成指定用途的操作令牌
+// 签名密钥由 JWT 密钥和用途派生，操作令牌不能当作访问令牌或其他用途的令牌使用
+func GenerateActionToken(purpose string, userID uint, value string, ttl time.Duration) (string, error) {
+	if len(jwtSecret) == 0 {
+		return "", errors.New("JWT密钥未初始化")
+	}
+
+	now := time.Now()
+	claims := ActionClaims{
+		UserID: userID,
+		Value:  value,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{purpose},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(actionKey(purpose))
+}
+
+// ParseActionToken 解析指定用途的操作令牌
+func ParseActionToken(purpose, tokenString string) (*ActionClaims, error) {
+	if len(jwtSecret) == 0 {
+		return nil, errors.New("JWT密钥未初始化")
+	}
+
+	claims := &ActionClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return actionKey(purpose), nil
+	}, jwt.WithAudience(purpose), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("无效的令牌")
+	}
+	return claims, nil
+}
+
+// actionKey 派生指定用途的签名密钥
+func actionKey(purpose string) []byte {
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte("blog-backend/action/" + purpose))
+	return mac.Sum(nil)
+}
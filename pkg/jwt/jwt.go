@@ -14,6 +14,7 @@ type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Version  int    `json:"ver,omitempty"` // 签发时用户的令牌版本，版本变化后令牌失效
 	jwt.RegisteredClaims
 }
 
@@ -24,13 +25,13 @@ func InitJWT(secret string) {
 	jwtSecret = []byte(secret)
 }
 
-// GenerateToken 生成JWT令牌
-func GenerateToken(userID uint, username, role string) (string, error) {
+// GenerateToken 生成JWT令牌，version 为用户当前的令牌版本
+func GenerateToken(userID uint, username, role string, version int) (string, error) {
 	if len(jwtSecret) == 0 {
 		return "", errors.New("JWT密钥未初始化")
 	}
 
-	cfg := config.GlobalConfig
+	cfg := config.Get()
 	now := time.Now()
 	expiresAt := now.Add(cfg.JWT.GetJWTExpireDuration())
 
@@ -38,6 +39,7 @@ func GenerateToken(userID uint, username, role string) (string, error) {
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		Version:  version,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -50,12 +52,12 @@ func GenerateToken(userID uint, username, role string) (string, error) {
 }
 
 // GenerateRefreshToken 生成刷新令牌
-func GenerateRefreshToken(userID uint, username, role string) (string, error) {
+func GenerateRefreshToken(userID uint, username, role string, version int) (string, error) {
 	if len(jwtSecret) == 0 {
 		return "", errors.New("JWT密钥未初始化")
 	}
 
-	cfg := config.GlobalConfig
+	cfg := config.Get()
 	now := time.Now()
 	expiresAt := now.Add(cfg.JWT.GetRefreshExpireDuration())
 
@@ -63,6 +65,7 @@ func GenerateRefreshToken(userID uint, username, role string) (string, error) {
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		Version:  version,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -103,5 +106,5 @@ func RefreshToken(tokenString string) (string, error) {
 	}
 
 	// 生成新的访问令牌
-	return GenerateToken(claims.UserID, claims.Username, claims.Role)
+	return GenerateToken(claims.UserID, claims.Username, claims.Role, claims.Version)
 }
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// GormLogger 基于zap的GORM日志适配器
+type GormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+	redactParams  bool
+}
+
+var (
+	_ gormlogger.Interface = (*GormLogger)(nil)
+	_ gorm.ParamsFilter    = (*GormLogger)(nil)
+)
+
+// NewGormLogger 创建GORM日志适配器
+// redactParams 为 true 时SQL中只保留占位符，不输出参数值
+func NewGormLogger(level gormlogger.LogLevel, slowThreshold time.Duration, redactParams bool) *GormLogger {
+	return &GormLogger{
+		level:         level,
+		slowThreshold: slowThreshold,
+		redactParams:  redactParams,
+	}
+}
+
+// LogMode 设置日志级别
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+// Info 信息日志
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger(ctx).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn 警告日志
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger(ctx).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error 错误日志
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger(ctx).Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace 记录SQL执行情况
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger(ctx).Error("SQL执行失败", sqlFields(sql, rows, elapsed, zap.Error(err))...)
+	case slow && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.logger(ctx).Warn("慢查询", sqlFields(sql, rows, elapsed, zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.logger(ctx).Info("SQL", sqlFields(sql, rows, elapsed)...)
+	}
+}
+
+// ParamsFilter 脱敏模式下丢弃SQL参数
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if l.redactParams {
+		return sql, nil
+	}
+	return sql, params
+}
+
+// logger 获取带请求上下文的Logger，调用位置由 source 字段给出
+func (l *GormLogger) logger(ctx context.Context) *zap.Logger {
+	return WithContext(ctx).WithOptions(zap.WithCaller(false)).With(zap.String("source", utils.FileWithLineNum()))
+}
+
+func sqlFields(sql string, rows int64, elapsed time.Duration, extra ...zap.Field) []zap.Field {
+	fields := []zap.Field{
+		zap.String("sql", sql),
+		zap.Duration("elapsed", elapsed),
+	}
+	if rows >= 0 {
+		fields = append(fields, zap.Int64("rows", rows))
+	}
+	return append(fields, extra...)
+}
@@ -1,35 +1,50 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/requestid"
 )
 
 var Logger *zap.Logger
 var SugaredLogger *zap.SugaredLogger
 
-// InitLogger 初始化日志系统
-func InitLogger(cfg *config.LogConfig) error {
-	// 设置日志级别
-	var level zapcore.Level
-	switch cfg.Level {
+// atomicLevel 日志级别，支持运行时修改
+var atomicLevel = zap.NewAtomicLevel()
+
+// parseLevel 解析日志级别
+func parseLevel(level string) zapcore.Level {
+	switch level {
 	case "debug":
-		level = zapcore.DebugLevel
+		return zapcore.DebugLevel
 	case "info":
-		level = zapcore.InfoLevel
+		return zapcore.InfoLevel
 	case "warn":
-		level = zapcore.WarnLevel
+		return zapcore.WarnLevel
 	case "error":
-		level = zapcore.ErrorLevel
+		return zapcore.ErrorLevel
 	default:
-		level = zapcore.InfoLevel
+		return zapcore.InfoLevel
 	}
+}
+
+// SetLevel 运行时修改日志级别
+func SetLevel(level string) {
+	atomicLevel.SetLevel(parseLevel(level))
+}
+
+// InitLogger 初始化日志系统
+func InitLogger(cfg *config.LogConfig) error {
+	// 设置日志级别
+	atomicLevel.SetLevel(parseLevel(cfg.Level))
 
 	// 编码器配置
 	encoderConfig := zapcore.EncoderConfig{
@@ -61,13 +76,13 @@ func InitLogger(cfg *config.LogConfig) error {
 		zapcore.NewCore(
 			zapcore.NewJSONEncoder(encoderConfig),
 			zapcore.AddSync(lumberJackLogger),
-			level,
+			atomicLevel,
 		),
 		// 控制台输出
 		zapcore.NewCore(
 			zapcore.NewConsoleEncoder(encoderConfig),
 			zapcore.AddSync(os.Stdout),
-			level,
+			atomicLevel,
 		),
 	)
 
@@ -91,6 +106,28 @@ func GetSugaredLogger() *zap.SugaredLogger {
 	return SugaredLogger
 }
 
+// WithContext 获取附带请求ID和链路追踪ID的Logger
+// 处理请求过程中的日志（中间件、控制器、服务）统一通过它记录，便于按请求关联
+func WithContext(ctx context.Context) *zap.Logger {
+	// 直接调用返回的Logger时不经过包装函数，需抵消 AddCallerSkip(1)
+	l := Logger.WithOptions(zap.AddCallerSkip(-1))
+	if ctx == nil {
+		return l
+	}
+
+	var fields []zap.Field
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", sc.TraceID().String()),
+			zap.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return l.With(fields...)
+}
+
 // Sync 同步日志
 func Sync() {
 	if Logger != nil {
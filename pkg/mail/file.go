@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer 把邮件写入目录中的 .eml 文件，不实际发送
+type fileMailer struct {
+	from *netmail.Address
+	dir  string
+}
+
+// Send 写入邮件文件，文件名为发送时间加随机标识，按名称排序即为发送顺序
+func (m *fileMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := encode(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("创建邮件目录失败: %w", err)
+	}
+
+	name := time.Now().Format("20060102-150405.000000") + "-" + randomID()[:8] + ".eml"
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("写入邮件文件失败: %w", err)
+	}
+	return nil
+}
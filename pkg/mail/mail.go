@@ -0,0 +1,144 @@
+// Package mail 邮件发送
+//
+// Mailer 接口有 SMTP 和文件两种实现，文件驱动把邮件写成 .eml 文件，便于本地开发时查看。
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	netmail "net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// SendTimeout 发送一封邮件的最长时间，包括连接、认证和传输
+const SendTimeout = 30 * time.Second
+
+// Message 邮件内容
+type Message struct {
+	To      string // 收件人地址
+	Subject string
+	Text    string // 纯文本正文
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New 根据配置创建邮件发送器
+func New(cfg *config.MailConfig) (Mailer, error) {
+	from, err := netmail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("解析发件人地址失败: %w", err)
+	}
+
+	switch cfg.GetDriver() {
+	case config.MailDriverSMTP:
+		return &smtpMailer{
+			from:     from,
+			host:     cfg.Host,
+			port:     cfg.Port,
+			username: cfg.Username,
+			password: cfg.Password,
+			tls:      cfg.TLS,
+			timeout:  SendTimeout,
+		}, nil
+	case config.MailDriverFile:
+		return &fileMailer{from: from, dir: cfg.OutboxDir}, nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件驱动: %s", cfg.Driver)
+	}
+}
+
+var (
+	mu      sync.RWMutex
+	current Mailer
+)
+
+// Init 根据配置初始化全局邮件发送器
+func Init(cfg *config.MailConfig) error {
+	m, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	SetMailer(m)
+	return nil
+}
+
+// SetMailer 替换全局邮件发送器，可用于接入其他邮件服务
+func SetMailer(m Mailer) {
+	mu.Lock()
+	current = m
+	mu.Unlock()
+}
+
+// Send 使用全局邮件发送器发送邮件，超过 SendTimeout 后放弃
+func Send(ctx context.Context, msg *Message) error {
+	mu.RLock()
+	m := current
+	mu.RUnlock()
+
+	if m == nil {
+		return errors.New("邮件服务未初始化")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, SendTimeout)
+	defer cancel()
+	return m.Send(ctx, msg)
+}
+
+// encode 生成 RFC 5322 格式的邮件，正文使用 base64 编码
+func encode(from *netmail.Address, msg *Message) ([]byte, error) {
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("解析收件人地址失败: %w", err)
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Text))
+	for len(body) > 76 {
+		buf.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	buf.WriteString(body + "\r\n")
+	return buf.Bytes(), nil
+}
+
+// messageID 生成唯一的 Message-ID，域名取自发件人地址
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return "<" + randomID() + "@" + domain + ">"
+}
+
+// randomID 生成随机标识
+func randomID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
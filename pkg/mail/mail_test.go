@@ -0,0 +1,209 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+func TestEncode(t *testing.T) {
+	from := &netmail.Address{Name: "博客", Address: "noreply@example.com"}
+	text := strings.Repeat("验证链接: https://example.com/verify?token=abc\n", 5)
+
+	tests := []struct {
+		name    string
+		to      string
+		subject string
+		wantTo  string
+	}{
+		{"ascii", "alice@example.com", "Verify your email", "alice@example.com"},
+		{"utf-8 subject", "Alice <alice@example.com>", "验证邮箱", "alice@example.com"},
+		{"utf-8 name", "爱丽丝 <alice@example.com>", "验证邮箱", "alice@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := encode(from, &Message{To: tt.to, Subject: tt.subject, Text: text})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg, err := netmail.ReadMessage(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var dec mime.WordDecoder
+			subject, err := dec.DecodeHeader(msg.Header.Get("Subject"))
+			if err != nil || subject != tt.subject {
+				t.Errorf("Subject = %q, %v, want %q", subject, err, tt.subject)
+			}
+			if to, err := msg.Header.AddressList("To"); err != nil || len(to) != 1 || to[0].Address != tt.wantTo {
+				t.Errorf("To = %v, %v", to, err)
+			}
+			if f, err := msg.Header.AddressList("From"); err != nil || f[0].Name != "博客" || f[0].Address != from.Address {
+				t.Errorf("From = %v, %v", f, err)
+			}
+			if id := msg.Header.Get("Message-ID"); !strings.HasSuffix(id, "@example.com>") {
+				t.Errorf("Message-ID = %q", id)
+			}
+			if _, err := msg.Header.Date(); err != nil {
+				t.Errorf("Date: %v", err)
+			}
+
+			// 正文按 base64 编码，每行不超过 76 个字符
+			raw, err := io.ReadAll(msg.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.Split(strings.TrimRight(string(raw), "\r\n"), "\r\n") {
+				if len(line) > 76 {
+					t.Errorf("body line length = %d", len(line))
+				}
+			}
+			body, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(raw), "\r\n", ""))
+			if err != nil || string(body) != text {
+				t.Errorf("body = %q, %v, want %q", body, err, text)
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsInvalidRecipient(t *testing.T) {
+	from := &netmail.Address{Address: "noreply@example.com"}
+	for _, to := range []string{"", "not-an-address", "alice@example.com\r\nBcc: eve@example.com"} {
+		if _, err := encode(from, &Message{To: to, Subject: "s", Text: "t"}); err == nil {
+			t.Errorf("encode(%q) succeeded", to)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	m, err := New(&config.MailConfig{Driver: config.MailDriverFile, From: "Blog <noreply@example.com>", OutboxDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subjects := []string{"first", "second", "third"}
+	for _, subject := range subjects {
+		if err := m.Send(context.Background(), &Message{To: "alice@example.com", Subject: subject, Text: "text"}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	// 按文件名排序即为发送顺序
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".eml" {
+			t.Errorf("outbox file %q", entry.Name())
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := netmail.ReadMessage(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, msg.Header.Get("Subject"))
+	}
+	if !slices.Equal(got, subjects) {
+		t.Errorf("outbox subjects = %v, want %v", got, subjects)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.MailConfig
+		wantErr bool
+	}{
+		{"file", config.MailConfig{From: "noreply@example.com"}, false},
+		{"smtp", config.MailConfig{Driver: config.MailDriverSMTP, From: "noreply@example.com", Host: "localhost", Port: 25}, false},
+		{"invalid from", config.MailConfig{From: "noreply"}, true},
+		{"unknown driver", config.MailConfig{Driver: "sendmail", From: "noreply@example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendWithoutMailer(t *testing.T) {
+	SetMailer(nil)
+	if err := Send(context.Background(), &Message{To: "alice@example.com"}); err == nil {
+		t.Error("Send() without mailer succeeded")
+	}
+}
+
+// deadlineMailer 记录 Send 收到的截止时间
+type deadlineMailer struct{ deadline time.Time }
+
+func (m *deadlineMailer) Send(ctx context.Context, msg *Message) error {
+	m.deadline, _ = ctx.Deadline()
+	return nil
+}
+
+func TestSendTimeout(t *testing.T) {
+	m := &deadlineMailer{}
+	SetMailer(m)
+	t.Cleanup(func() { SetMailer(nil) })
+
+	if err := Send(context.Background(), &Message{To: "alice@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if m.deadline.IsZero() || time.Until(m.deadline) > SendTimeout {
+		t.Errorf("Send() deadline = %v, want within %v", m.deadline, SendTimeout)
+	}
+}
+
+func TestSMTPStalledServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// 接受连接后不发送问候，模拟无响应的服务器
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	m := &smtpMailer{
+		from:    &netmail.Address{Address: "noreply@example.com"},
+		host:    "127.0.0.1",
+		port:    addr.Port,
+		timeout: 100 * time.Millisecond,
+	}
+	start := time.Now()
+	if err := m.Send(context.Background(), &Message{To: "alice@example.com", Subject: "hi", Text: "hi"}); err == nil {
+		t.Fatal("Send() to a stalled server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Send() to a stalled server took %v", elapsed)
+	}
+}
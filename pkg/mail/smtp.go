@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// dialTimeout 连接 SMTP 服务器的超时时间
+const dialTimeout = 10 * time.Second
+
+// smtpMailer 通过 SMTP 服务器发送邮件
+type smtpMailer struct {
+	from     *netmail.Address
+	host     string
+	port     int
+	username string
+	password string
+	tls      bool
+	timeout  time.Duration // context 没有截止时间时使用的超时时间
+}
+
+// Send 发送邮件，未使用隐式 TLS 时在服务器支持的情况下升级为 STARTTLS
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := encode(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	tlsConfig := &tls.Config{ServerName: m.host}
+
+	var conn net.Conn
+	if m.tls {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	// 服务器无响应时连接不会一直挂起
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(m.timeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+	defer client.Close()
+
+	if !m.tls {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("STARTTLS失败: %w", err)
+			}
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start_time"
+
+// GormPlugin 通过回调采集GORM查询耗时和错误
+type GormPlugin struct{}
+
+// Name 插件名称
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize 注册回调
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", p.before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", p.after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", p.before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", p.after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", p.before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", p.after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", p.before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", p.after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", p.before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", p.after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", p.before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", p.after("raw"))
+}
+
+func (GormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func (GormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		DBQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			DBQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
+
+// RegisterDBStats 注册连接池状态指标
+func RegisterDBStats(sqlDB *sql.DB, dbName string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(sqlDB, dbName))
+}
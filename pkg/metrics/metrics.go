@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blog"
+
+// Registry 应用指标注册表
+var Registry = prometheus.NewRegistry()
+
+// HTTP 指标
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP请求总数",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP请求耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// 数据库与Redis指标
+var (
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "GORM查询耗时（秒）",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	DBQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_errors_total",
+		Help:      "GORM查询错误总数",
+	}, []string{"operation", "table"})
+
+	RedisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "command_duration_seconds",
+		Help:      "Redis命令耗时（秒）",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+	}, []string{"command"})
+
+	RedisCommandErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "command_errors_total",
+		Help:      "Redis命令错误总数",
+	}, []string{"command"})
+)
+
+// 业务指标
+var (
+	UserRegistrations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_registrations_total",
+		Help:      "用户注册总数",
+	})
+
+	UserLogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "user_logins_total",
+		Help:      "用户登录总数",
+	}, []string{"result"})
+
+	ArticlesPublished = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "articles_published_total",
+		Help:      "发布文章总数",
+	})
+
+	FileUploads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "file_uploads_total",
+		Help:      "文件上传总数",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		DBQueryErrors,
+		RedisCommandDuration,
+		RedisCommandErrors,
+		UserRegistrations,
+		UserLogins,
+		ArticlesPublished,
+		FileUploads,
+	)
+}
+
+// Handler 返回 Prometheus 文本格式的指标处理器
+func Handler() gin.HandlerFunc {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	return gin.WrapH(h)
+}
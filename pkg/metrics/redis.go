@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisStartKey struct{}
+
+// RedisHook 采集Redis命令耗时和错误
+type RedisHook struct{}
+
+var _ redis.Hook = RedisHook{}
+
+// BeforeProcess 记录命令开始时间
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+// AfterProcess 记录命令耗时
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeRedis(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+// BeforeProcessPipeline 记录管道开始时间
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+// AfterProcessPipeline 记录管道耗时
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	observeRedis(ctx, "pipeline", err)
+	return nil
+}
+
+func observeRedis(ctx context.Context, command string, err error) {
+	start, ok := ctx.Value(redisStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+
+	RedisCommandDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+	if err != nil && err != redis.Nil {
+		RedisCommandErrors.WithLabelValues(command).Inc()
+	}
+}
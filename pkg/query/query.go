@@ -0,0 +1,214 @@
+// Package query 列表查询参数解析
+//
+// 排序和字段选择只接受白名单中的名称，解析结果是白名单中的内部名称，客户端输入不会直接拼接到 SQL 中。
+// Parser 收集所有参数的错误，便于一次性返回给客户端。
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error 单个查询参数的错误
+type Error struct {
+	Param   string   // 参数名
+	Value   string   // 不合法的值
+	Allowed []string // 允许的取值，为空表示格式错误
+}
+
+func (e *Error) Error() string {
+	if len(e.Allowed) > 0 {
+		return fmt.Sprintf("参数 %s 的值 %q 无效，可选值: %s", e.Param, e.Value, strings.Join(e.Allowed, ", "))
+	}
+	return fmt.Sprintf("参数 %s 的值 %q 格式错误", e.Param, e.Value)
+}
+
+// Errors 多个查询参数的错误
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Whitelist 允许客户端使用的名称，键为参数中的名称，值为内部名称（如数据库列名）
+type Whitelist map[string]string
+
+// Names 允许的名称，按字母排序
+func (w Whitelist) Names() []string {
+	names := make([]string, 0, len(w))
+	for name := range w {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Order 排序字段
+type Order struct {
+	Field string // 白名单中的内部名称
+	Desc  bool
+}
+
+// Parser 查询参数解析器，零值可用，解析失败的参数返回零值并记录错误
+type Parser struct {
+	errs Errors
+}
+
+// Err 返回解析过程中的全部错误，没有错误时返回 nil
+func (p *Parser) Err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return p.errs
+}
+
+func (p *Parser) fail(param, value string, allowed []string) {
+	p.errs = append(p.errs, &Error{Param: param, Value: value, Allowed: allowed})
+}
+
+// Sort 解析排序参数，如 "-views,created_at"，"-" 前缀表示倒序，最多 max 个字段
+func (p *Parser) Sort(param, raw string, allowed Whitelist, max int) []Order {
+	var orders []Order
+	seen := make(map[string]bool)
+	for _, item := range split(raw) {
+		name, desc := strings.CutPrefix(item, "-")
+		field, ok := allowed[name]
+		if !ok {
+			p.fail(param, item, sortNames(allowed))
+			return nil
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		orders = append(orders, Order{Field: field, Desc: desc})
+	}
+
+	if max > 0 && len(orders) > max {
+		p.fail(param, raw, nil)
+		return nil
+	}
+	return orders
+}
+
+// sortNames 排序参数允许的取值，包含倒序形式
+func sortNames(allowed Whitelist) []string {
+	var names []string
+	for _, name := range allowed.Names() {
+		names = append(names, name, "-"+name)
+	}
+	return names
+}
+
+// Fields 解析字段选择参数，如 "id,title"，返回去重后的参数名称，raw 为空时返回 nil
+func (p *Parser) Fields(param, raw string, allowed Whitelist) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, name := range split(raw) {
+		if _, ok := allowed[name]; !ok {
+			p.fail(param, name, allowed.Names())
+			return nil
+		}
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// Uints 解析逗号分隔的ID列表，如 "1,2,3"
+func (p *Parser) Uints(param, raw string) []uint {
+	var ids []uint
+	for _, item := range split(raw) {
+		id, err := strconv.ParseUint(item, 10, 32)
+		if err != nil || id == 0 {
+			p.fail(param, item, nil)
+			return nil
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// Time 解析时间，支持 RFC3339 和 2006-01-02 格式，raw 为空时返回 nil
+// end 为 true 且只给出日期时返回次日零点，作为不包含的上界，使结束日期当天的记录也能匹配
+func (p *Parser) Time(param, raw string, end bool) *time.Time {
+	if raw == "" {
+		return nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t
+	}
+	t, err := time.ParseInLocation(time.DateOnly, raw, time.Local)
+	if err != nil {
+		p.fail(param, raw, nil)
+		return nil
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t
+}
+
+// split 按逗号分隔并去掉空白和空项
+func split(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Pick 只保留 v 序列化为 JSON 后的指定字段，v 为结构体或结构体切片，fields 为空时原样返回
+func Pick(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(data) == "null" {
+		return v, nil
+	}
+	if strings.HasPrefix(string(data), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i] = pick(items[i], fields)
+		}
+		return items, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	return pick(item, fields), nil
+}
+
+func pick(item map[string]json.RawMessage, fields []string) map[string]json.RawMessage {
+	picked := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		if value, ok := item[field]; ok {
+			picked[field] = value
+		}
+	}
+	return picked
+}
@@ -0,0 +1,163 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testSorts = Whitelist{
+	"created_at": "created_at",
+	"views":      "view_count",
+}
+
+func TestParserSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		max     int
+		want    []Order
+		wantErr bool
+	}{
+		{"empty", "", 1, nil, false},
+		{"ascending", "views", 1, []Order{{Field: "view_count"}}, false},
+		{"descending", "-views", 1, []Order{{Field: "view_count", Desc: true}}, false},
+		{"multiple", " -views , created_at ", 2, []Order{{Field: "view_count", Desc: true}, {Field: "created_at"}}, false},
+		{"duplicate", "views,-views", 1, []Order{{Field: "view_count"}}, false},
+		{"internal name", "view_count", 1, nil, true},
+		{"sql injection", "views;DROP TABLE articles", 1, nil, true},
+		{"double minus", "--views", 1, nil, true},
+		{"too many", "views,created_at", 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Parser
+			got := p.Sort("sort", tt.raw, testSorts, tt.max)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+			if (p.Err() != nil) != tt.wantErr {
+				t.Errorf("Sort() error = %v, wantErr %v", p.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParserSortErrorListsAllowed(t *testing.T) {
+	var p Parser
+	p.Sort("sort", "title", testSorts, 1)
+
+	var errs Errors
+	if !errors.As(p.Err(), &errs) || len(errs) != 1 {
+		t.Fatalf("Err() = %v, want one error", p.Err())
+	}
+	want := []string{"created_at", "-created_at", "views", "-views"}
+	if errs[0].Param != "sort" || errs[0].Value != "title" || !reflect.DeepEqual(errs[0].Allowed, want) {
+		t.Errorf("Err() = %+v, want allowed %v", errs[0], want)
+	}
+}
+
+func TestParserFields(t *testing.T) {
+	fields := Whitelist{"id": "id", "title": "title"}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"valid", "title,id,title", []string{"title", "id"}, false},
+		{"unknown", "id,content", nil, true},
+		{"column expression", "id,(SELECT password FROM users)", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Parser
+			got := p.Fields("fields", tt.raw, fields)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %v, want %v", got, tt.want)
+			}
+			if (p.Err() != nil) != tt.wantErr {
+				t.Errorf("Fields() error = %v, wantErr %v", p.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParserCollectsErrors(t *testing.T) {
+	var p Parser
+	p.Sort("sort", "title", testSorts, 1)
+	p.Uints("tags", "1,x")
+	p.Time("created_from", "yesterday", false)
+
+	var errs Errors
+	if !errors.As(p.Err(), &errs) || len(errs) != 3 {
+		t.Fatalf("Err() = %v, want three errors", p.Err())
+	}
+	for i, param := range []string{"sort", "tags", "created_from"} {
+		if errs[i].Param != param {
+			t.Errorf("errs[%d].Param = %q, want %q", i, errs[i].Param, param)
+		}
+	}
+}
+
+func TestParserUints(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    []uint
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"1, 2,3", []uint{1, 2, 3}, false},
+		{"0", nil, true},
+		{"-1", nil, true},
+		{"99999999999", nil, true},
+	}
+	for _, tt := range tests {
+		var p Parser
+		got := p.Uints("tags", tt.raw)
+		if !reflect.DeepEqual(got, tt.want) || (p.Err() != nil) != tt.wantErr {
+			t.Errorf("Uints(%q) = %v, %v", tt.raw, got, p.Err())
+		}
+	}
+}
+
+func TestParserTime(t *testing.T) {
+	var p Parser
+
+	got := p.Time("created_to", "2024-03-01", true)
+	if want := time.Date(2024, 3, 2, 0, 0, 0, 0, time.Local); got == nil || !got.Equal(want) {
+		t.Errorf("Time() end date = %v, want %v", got, want)
+	}
+	got = p.Time("created_from", "2024-03-01", false)
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local); got == nil || !got.Equal(want) {
+		t.Errorf("Time() start date = %v, want %v", got, want)
+	}
+	got = p.Time("created_from", "2024-03-01T08:00:00Z", true)
+	if want := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC); got == nil || !got.Equal(want) {
+		t.Errorf("Time() RFC3339 = %v, want %v", got, want)
+	}
+	if err := p.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestPick(t *testing.T) {
+	type item struct {
+		ID    uint   `json:"id"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+
+	got, err := Pick([]item{{ID: 1, Title: "a", Body: "x"}}, []string{"id", "title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, ok := got.([]map[string]json.RawMessage)
+	if !ok || len(items) != 1 || len(items[0]) != 2 || string(items[0]["title"]) != `"a"` {
+		t.Errorf("Pick() = %v", got)
+	}
+}
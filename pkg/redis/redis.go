@@ -9,6 +9,8 @@ import (
 	"github.com/go-redis/redis/v8"
 
 	"github.com/xiaoxin/blog-backend/pkg/config"
+	"github.com/xiaoxin/blog-backend/pkg/metrics"
+	"github.com/xiaoxin/blog-backend/pkg/tracing"
 )
 
 var Client *redis.Client
@@ -23,6 +25,10 @@ func InitRedis(cfg *config.RedisConfig) error {
 		PoolSize: cfg.PoolSize,
 	})
 
+	// 注册命令指标与追踪钩子
+	client.AddHook(metrics.RedisHook{})
+	client.AddHook(tracing.RedisHook{})
+
 	// 测试连接
 	_, err := client.Ping(Ctx).Result()
 	if err != nil {
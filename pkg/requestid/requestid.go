@@ -0,0 +1,40 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// HeaderName 请求ID头
+const HeaderName = "X-Request-ID"
+
+type ctxKey struct{}
+
+// validID 允许透传的请求ID格式，防止日志注入
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// New 生成新的请求ID
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid 检查外部传入的请求ID是否可用
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// NewContext 将请求ID写入上下文
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext 从上下文中读取请求ID
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin 为每条GORM语句创建span
+type GormPlugin struct{}
+
+// Name 插件名称
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 注册回调
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("tracing:after_create", p.after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("tracing:after_query", p.after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tracing:after_update", p.after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("tracing:after_row", p.after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after)
+}
+
+func (GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			return
+		}
+
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (GormPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	// SQL使用占位符形式，不记录参数值
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		semconv.DBResponseReturnedRowsKey.Int64(db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/xiaoxin/blog-backend/pkg/config"
+)
+
+// TracerName 本应用使用的 Tracer 名称
+const TracerName = "github.com/xiaoxin/blog-backend"
+
+var provider *sdktrace.TracerProvider
+
+// InitTracer 初始化链路追踪
+func InitTracer(cfg *config.TracingConfig, serviceName, version string) error {
+	// 无论是否开启追踪，都使用 W3C Trace Context 传播
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return nil
+	}
+
+	exporter, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+
+	res, err := resource.New(context.Background(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(version),
+		),
+	)
+	if err != nil {
+		return fmt.Errorf("创建追踪资源失败: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	return nil
+}
+
+// newExporter 根据配置创建导出器
+func newExporter(cfg *config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "", "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("创建stdout导出器失败: %w", err)
+		}
+		return exporter, nil
+	case "otlp":
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("创建OTLP导出器失败: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("不支持的追踪导出器: %s", cfg.Exporter)
+	}
+}
+
+// Tracer 获取应用 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Shutdown 刷新并关闭追踪导出器
+func Shutdown() error {
+	if provider == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return provider.Shutdown(ctx)
+}